@@ -0,0 +1,21 @@
+# list deploy settings of the main branch
+data "travis_env_vars" "deploy" {
+  repository_slug = "bgpat/test"
+  name_regex      = "^DEPLOY_"
+  branch          = "main"
+}
+
+# copy the public variables to another repository
+resource "travis_env_vars" "copy" {
+  repository_slug = "bgpat/test2"
+
+  dynamic "variable" {
+    for_each = [for v in data.travis_env_vars.deploy.env_vars : v if v.public]
+    content {
+      name   = variable.value.name
+      value  = variable.value.value
+      public = true
+      branch = variable.value.branch
+    }
+  }
+}
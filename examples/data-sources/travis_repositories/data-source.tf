@@ -0,0 +1,9 @@
+# list repositories of the current user
+data "travis_repositories" "current" {}
+
+# list active repositories of an organization
+data "travis_repositories" "org" {
+  owner      = "bgpat"
+  active     = true
+  slug_regex = "^bgpat/terraform-"
+}
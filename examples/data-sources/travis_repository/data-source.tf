@@ -0,0 +1,9 @@
+# get repository by slug
+data "travis_repository" "by_slug" {
+  repository_slug = "bgpat/test"
+}
+
+# get repository by repository_id
+data "travis_repository" "by_id" {
+  repository_id = 2562785
+}
@@ -0,0 +1,20 @@
+# Travis CI (travis-ci.com)
+provider "travis" {
+  token = var.travis_token
+}
+
+# Token read from an external helper
+provider "travis" {
+  alias         = "vault"
+  token_command = ["vault", "kv", "get", "-field=token", "secret/travis"]
+}
+
+# Travis CI Enterprise
+provider "travis" {
+  alias    = "enterprise"
+  hostname = "travis.example.com"
+  token    = var.travis_enterprise_token
+
+  # used by env vars, crons and key pairs specifying neither repository_id nor repository_slug
+  default_repository_slug = "my-org/my-repo"
+}
@@ -1,5 +1,8 @@
-# ${repository_id}/${name}
+# ${repository_slug}/${branch}
 terraform import travis_cron.main_daily bgpat/test/main
 
-# ${repository_slug}/${name}
+# ${repository_id}/${branch}
 terraform import travis_cron.main_daily 2562785/main
+
+# ${cron_id}
+terraform import travis_cron.main_daily 123456
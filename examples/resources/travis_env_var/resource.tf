@@ -10,3 +10,12 @@ resource "travis_env_var" "secret_values" {
   name          = "SECRET_VALUE_${upper(each.key)}"
   value         = each.value
 }
+
+# the value is never stored in the state; a changed value is written again by its hash,
+# and bumping value_wo_version restores a value changed outside of Terraform
+resource "travis_env_var" "write_only" {
+  repository_slug  = "bgpat/test"
+  name             = "WRITE_ONLY_VALUE"
+  value_wo         = "secret"
+  value_wo_version = 1
+}
@@ -0,0 +1,5 @@
+# ${repository_slug}
+terraform import travis_env_vars.test bgpat/test
+
+# ${repository_id}
+terraform import travis_env_vars.test 2562785
@@ -0,0 +1,24 @@
+resource "travis_env_vars" "test" {
+  repository_slug = "bgpat/test"
+
+  # delete the variables not declared below
+  exclusive = true
+
+  variable {
+    name   = "PUBLIC_VALUE"
+    value  = "public"
+    public = true
+  }
+
+  variable {
+    name  = "SECRET_VALUE"
+    value = "secret"
+  }
+
+  variable {
+    name   = "DEPLOY_TARGET"
+    value  = "production"
+    public = true
+    branch = "main"
+  }
+}
@@ -0,0 +1,5 @@
+# ${repository_slug}
+terraform import travis_generated_key_pair.deploy bgpat/test
+
+# ${repository_id}
+terraform import travis_generated_key_pair.deploy 2562785
@@ -0,0 +1,10 @@
+resource "travis_generated_key_pair" "deploy" {
+  repository_slug = "bgpat/test"
+}
+
+resource "github_repository_deploy_key" "travis" {
+  repository = "test"
+  title      = "Travis CI"
+  key        = travis_generated_key_pair.deploy.public_key
+  read_only  = true
+}
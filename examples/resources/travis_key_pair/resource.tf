@@ -0,0 +1,10 @@
+resource "tls_private_key" "deploy" {
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}
+
+resource "travis_key_pair" "deploy" {
+  repository_slug = "bgpat/test"
+  description     = "deploy key"
+  value           = tls_private_key.deploy.private_key_pem
+}
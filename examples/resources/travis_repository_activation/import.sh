@@ -0,0 +1,5 @@
+# ${repository_slug}
+terraform import travis_repository_activation.test bgpat/test
+
+# ${repository_id}
+terraform import travis_repository_activation.test 2562785
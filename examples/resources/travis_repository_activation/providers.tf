@@ -0,0 +1,7 @@
+terraform {
+  required_providers {
+    travis = {
+      source = "bgpat/travis"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+resource "travis_repository_activation" "test" {
+  repository_slug = "bgpat/test"
+}
+
+resource "travis_env_var" "test" {
+  repository_id = travis_repository_activation.test.id
+  name          = "FOO"
+  public_value  = "bar"
+}
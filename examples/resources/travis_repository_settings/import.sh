@@ -0,0 +1,5 @@
+# ${repository_slug}
+terraform import travis_repository_settings.test bgpat/test
+
+# ${repository_id}
+terraform import travis_repository_settings.test 2562785
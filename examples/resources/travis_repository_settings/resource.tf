@@ -0,0 +1,9 @@
+resource "travis_repository_settings" "test" {
+  repository_slug             = "bgpat/test"
+  builds_only_with_travis_yml = true
+  build_pushes                = true
+  build_pull_requests         = true
+  maximum_number_of_builds    = 2
+  auto_cancel_pushes          = true
+  auto_cancel_pull_requests   = true
+}
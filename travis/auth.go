@@ -0,0 +1,119 @@
+package travis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/shuheiktgw/go-travis"
+)
+
+// ExchangeGitHubToken obtains a Travis CI token authenticated by the GitHub token.
+// go-travis does not cover the endpoint since it belongs to API v2, so the request is built manually.
+func (c *Client) ExchangeGitHubToken(ctx context.Context, githubToken string) (string, error) {
+	u, err := c.BaseURL.Parse("auth/github")
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"github_token": githubToken})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.travis-ci.2.1+json")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%s %s: %s %s", req.Method, u, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var auth struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("failed to decode the response of %s: %w", u, err)
+	}
+	if auth.AccessToken == "" {
+		return "", errors.New("no access token returned")
+	}
+	return auth.AccessToken, nil
+}
+
+// TokenFromGitHub returns TokenLoader exchanging the GitHub token for a Travis CI token by the client.
+func TokenFromGitHub(c *Client, githubToken string) TokenLoader {
+	return func(ctx context.Context) (string, error) {
+		token, err := c.ExchangeGitHubToken(ctx, githubToken)
+		if err != nil {
+			return "", fmt.Errorf("failed to exchange GitHub token: %w", err)
+		}
+		return token, nil
+	}
+}
+
+// validateCredentials checks the token by getting the current user.
+func validateCredentials(ctx context.Context, c *Client, token string) diag.Diagnostics {
+	apiURL := c.BaseURL.String()
+	if token == "" {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Travis CI token is missing",
+			Detail: fmt.Sprintf("No token is configured for %s. "+
+				"Set one of token, token_file, token_command or github_token, "+
+				"or set skip_credentials_validation to use the API without a token.", apiURL),
+		}}
+	}
+
+	user, _, err := c.User.Current(ctx, nil)
+	if err != nil {
+		var errResp *travis.ErrorResponse
+		if errors.As(err, &errResp) {
+			switch errResp.ErrorType {
+			case "login_required":
+				return diag.Diagnostics{{
+					Severity: diag.Error,
+					Summary:  "Travis CI token is invalid",
+					Detail:   fmt.Sprintf("%s rejected the token; it may be expired or issued for another Travis CI: %v", apiURL, err),
+				}}
+			case "insufficient_access":
+				return diag.Diagnostics{{
+					Severity: diag.Error,
+					Summary:  "Travis CI token lacks access",
+					Detail:   fmt.Sprintf("%s does not allow the token to get the current user: %v", apiURL, err),
+				}}
+			}
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "failed to validate Travis CI credentials",
+			Detail: fmt.Sprintf("Failed to get the current user from %s: %v. "+
+				"Set skip_credentials_validation to skip the validation.", apiURL, err),
+		}}
+	}
+
+	login := ""
+	if user.Login != nil {
+		login = *user.Login
+	}
+	tflog.Info(ctx, "authenticated travis API", map[string]interface{}{
+		"url":   apiURL,
+		"login": login,
+	})
+	return nil
+}
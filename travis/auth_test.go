@@ -0,0 +1,147 @@
+package travis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func newGitHubAuthTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user" && r.Header.Get("Authorization") == "token travis-token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"@type": "user", "id": 1, "login": "github-user"})
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/auth/github" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if v := r.Header.Get("Travis-API-Version"); v != "" {
+			t.Errorf("unexpected Travis-API-Version header: %q", v)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if body["github_token"] != "github-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("not a Travis user"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "travis-token"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_ExchangeGitHubToken(t *testing.T) {
+	server := newGitHubAuthTestServer(t)
+	client := NewClient(server.URL+"/", "")
+
+	token, err := client.ExchangeGitHubToken(context.Background(), "github-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "travis-token" {
+		t.Errorf("unexpected token: %q", token)
+	}
+
+	if _, err := client.ExchangeGitHubToken(context.Background(), "invalid"); err == nil {
+		t.Error("expected an error for the invalid GitHub token")
+	}
+}
+
+func TestProvider_githubToken(t *testing.T) {
+	t.Setenv("TRAVIS_TOKEN", "")
+	t.Setenv("TRAVIS_TOKEN_FILE", "")
+	server := newGitHubAuthTestServer(t)
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_base_url": server.URL + "/",
+		"github_token": "github-token",
+	}))
+	if diags.HasError() {
+		t.Fatalf("failed to configure: %v", diags)
+	}
+	transport := p.Meta().(*Client).HTTPClient.Transport.(*roundTripper)
+	if transport.tokens == nil || transport.tokens.current != "travis-token" {
+		t.Error("the exchanged token is not used")
+	}
+
+	diags = Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_base_url": server.URL + "/",
+		"github_token": "invalid",
+	}))
+	if !diags.HasError() {
+		t.Error("expected an error for the invalid GitHub token")
+	}
+
+	// GITHUB_TOKEN of CI jobs does not conflict with the token, which takes precedence
+	t.Setenv("GITHUB_TOKEN", "invalid")
+	diags = Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_base_url": server.URL + "/",
+		"token":        "travis-token",
+	}))
+	if diags.HasError() {
+		t.Errorf("the token must be used instead of GITHUB_TOKEN: %v", diags)
+	}
+}
+
+func TestProvider_validateCredentials(t *testing.T) {
+	t.Setenv("TRAVIS_TOKEN", "")
+	t.Setenv("TRAVIS_TOKEN_FILE", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("TRAVIS_SKIP_CREDENTIALS_VALIDATION", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "token valid":
+			_, _ = w.Write([]byte(`{"@type": "user", "id": 1, "login": "octocat"}`))
+		case "token unprivileged":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"@type": "error", "error_type": "insufficient_access", "error_message": "forbidden"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"@type": "error", "error_type": "login_required", "error_message": "login required"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	for name, tc := range map[string]struct {
+		config  map[string]interface{}
+		summary string
+	}{
+		"valid":        {config: map[string]interface{}{"token": "valid"}},
+		"missing":      {config: map[string]interface{}{}, summary: "Travis CI token is missing"},
+		"invalid":      {config: map[string]interface{}{"token": "invalid"}, summary: "Travis CI token is invalid"},
+		"unprivileged": {config: map[string]interface{}{"token": "unprivileged"}, summary: "Travis CI token lacks access"},
+		"skip":         {config: map[string]interface{}{"token": "invalid", "skip_credentials_validation": true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.config["api_base_url"] = server.URL + "/"
+			tc.config["max_retries"] = 0
+			diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(tc.config))
+			if tc.summary == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || diags[0].Summary != tc.summary {
+				t.Fatalf("expected %q, but got %v", tc.summary, diags)
+			}
+		})
+	}
+}
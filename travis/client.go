@@ -1,43 +1,416 @@
 package travis
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/cenkalti/backoff/v7"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/shuheiktgw/go-travis"
 )
 
+const retryInitialInterval = 500 * time.Millisecond
+
 // Client is an API client for Travis CI.
 type Client struct {
 	*travis.Client
+
+	// apiResources is the set of the resource types provided by the API, or nil if unknown.
+	apiResources map[string]bool
+
+	// the repository used by resources specifying neither repository_id nor repository_slug
+	defaultRepositoryID   int
+	defaultRepositorySlug string
+
+	repositories *repositoryResolver
+}
+
+// ClientOption configures the HTTP transport of Client.
+type ClientOption func(*roundTripper)
+
+// WithRetry retries requests failed by rate limits or transient errors up to maxRetries times.
+// The wait between attempts, including the one requested by Retry-After, never exceeds maxWait.
+func WithRetry(maxRetries int, maxWait time.Duration) ClientOption {
+	return func(r *roundTripper) {
+		r.maxRetries = maxRetries
+		r.retryMaxWait = maxWait
+	}
+}
+
+// WithMaxParallelWrites limits the number of concurrent write requests across all repositories.
+// Zero or a negative value means no limit.
+func WithMaxParallelWrites(n int) ClientOption {
+	return func(r *roundTripper) {
+		if n > 0 {
+			r.writeSlots = make(chan struct{}, n)
+		} else {
+			r.writeSlots = nil
+		}
+	}
+}
+
+// WithRateLimit limits requests to rps per second on average, allowing bursts of burst requests.
+// Zero or a negative rps means no limit.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(r *roundTripper) {
+		if rps > 0 {
+			r.limiter = newRateLimiter(rps, burst)
+		} else {
+			r.limiter = nil
+		}
+	}
+}
+
+// WithRequestTimeout limits the time of each attempt of requests, including reading the response body.
+// Zero or a negative value means no timeout.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(r *roundTripper) {
+		r.requestTimeout = timeout
+	}
+}
+
+// WithTokenLoader authorizes requests by the token loaded by load.
+// The initial token is used until the API rejects it, then the token is loaded again
+// so that it can be rotated during the run.
+func WithTokenLoader(initial string, load TokenLoader) ClientOption {
+	return func(r *roundTripper) {
+		r.tokens = &tokenSource{
+			load:           load,
+			reloadInterval: tokenReloadInterval,
+			current:        initial,
+			loadedAt:       time.Now(),
+		}
+	}
 }
 
 // NewClient returns an API client object.
-func NewClient(url, token string) *Client {
+func NewClient(baseURL, token string, opts ...ClientOption) *Client {
 	client := &Client{
-		Client: travis.NewClient(url, token),
+		Client:       travis.NewClient(baseURL, token),
+		repositories: newRepositoryResolver(),
+	}
+	transport := newRoundTripper(http.DefaultTransport, opts...)
+	transport.resolveRepository = func(ctx context.Context, slug string) (int, error) {
+		id, _, err := client.resolveRepository(ctx, 0, slug)
+		return id, err
 	}
-	client.HTTPClient = &http.Client{Transport: &roundTripper{base: http.DefaultTransport}}
+	client.HTTPClient = &http.Client{Transport: transport}
 	return client
 }
 
 type roundTripper struct {
 	base http.RoundTripper
-	mu   sync.Mutex
+
+	// writes to the same repository are serialized, and writeSlots bounds them in total
+	mu         sync.Mutex
+	repoLocks  map[string]*sync.Mutex
+	writeSlots chan struct{}
+	// resolveRepository returns the ID of the repository slug so that writes addressed by the ID and the slug share the lock
+	resolveRepository func(ctx context.Context, slug string) (int, error)
+
+	limiter        *rateLimiter
+	requestTimeout time.Duration
+	tokens         *tokenSource
+
+	maxRetries           int
+	retryMaxWait         time.Duration
+	retryInitialInterval time.Duration
+}
+
+func newRoundTripper(base http.RoundTripper, opts ...ClientOption) *roundTripper {
+	r := &roundTripper{
+		base:                 base,
+		repoLocks:            map[string]*sync.Mutex{},
+		retryInitialInterval: retryInitialInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// retryableStatusError is returned for responses which may succeed when retried.
+type retryableStatusError struct {
+	statusCode int
+}
+
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("retryable status %d", e.statusCode)
 }
 
 func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
 	if req.Method != http.MethodGet {
-		r.mu.Lock()
-		defer r.mu.Unlock()
+		unlock, err := r.lockWrite(req)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
 	}
-	tflog.Debug(req.Context(), "request travis API", map[string]interface{}{
+
+	if r.tokens == nil {
+		return r.send(req, false)
+	}
+	token, err := r.tokens.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.send(withToken(req, token), false)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	// the token may have been rotated during the run
+	newToken, err := r.tokens.refresh(ctx, token)
+	if err != nil {
+		tflog.Warn(ctx, "failed to reload travis token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return resp, nil
+	}
+	if newToken == token {
+		return resp, nil
+	}
+	tflog.Info(ctx, "retry with the reloaded travis token", map[string]interface{}{
 		"method": req.Method,
 		"url":    req.URL.String(),
 	})
-	return r.base.RoundTrip(req)
+	resp.Body.Close()
+	return r.send(withToken(req, newToken), true)
+}
+
+// send sends the request, retrying it on rate limits or transient errors.
+// If rewind is true, the body of req is read again from the beginning.
+func (r *roundTripper) send(req *http.Request, rewind bool) (*http.Response, error) {
+	ctx := req.Context()
+	eb := backoff.NewExponentialBackOff()
+	eb.InitialInterval = r.retryInitialInterval
+	if r.retryMaxWait > 0 {
+		eb.MaxInterval = r.retryMaxWait
+	}
+
+	attempt := 0
+	resp, err := backoff.Retry(ctx, func() (*http.Response, error) {
+		attempt++
+		if r.limiter != nil {
+			if err := r.limiter.wait(ctx); err != nil {
+				return nil, backoff.Permanent(err)
+			}
+		}
+		tflog.Debug(ctx, "request travis API", map[string]interface{}{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"attempt": attempt,
+		})
+		attemptReq, cancel := r.withRequestTimeout(rewindRequest(req, rewind || attempt > 1))
+		resp, err := r.base.RoundTrip(attemptReq)
+		if err != nil {
+			cancel()
+			if isIdempotent(req.Method) {
+				return nil, err
+			}
+			return nil, backoff.Permanent(err)
+		}
+		if !isRetryableStatus(req.Method, resp.StatusCode) {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		// buffer the body so that the last response can be returned after giving up
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		statusErr := &retryableStatusError{statusCode: resp.StatusCode}
+		if wait, ok := retryAfter(resp); ok {
+			if r.retryMaxWait > 0 && wait > r.retryMaxWait {
+				wait = r.retryMaxWait
+			}
+			return resp, backoff.RetryAfter(wait, statusErr)
+		}
+		return resp, statusErr
+	},
+		backoff.WithBackOff(eb),
+		backoff.WithMaxTries(uint(r.maxRetries)+1),
+		backoff.WithMaxElapsedTime(0),
+		backoff.WithNotify(func(err error, d time.Duration) {
+			tflog.Debug(ctx, "retry to request travis API", map[string]interface{}{
+				"method": req.Method,
+				"url":    req.URL.String(),
+				"reason": err,
+				"sleep":  d,
+			})
+		}),
+	)
+	if err != nil {
+		var statusErr *retryableStatusError
+		if resp != nil && errors.As(err, &statusErr) && ctx.Err() == nil {
+			// gave up retrying, so let the caller handle the error response
+			return resp, nil
+		}
+		if retryErr := backoff.AsRetryError(err); retryErr != nil {
+			return nil, retryErr.LastErr
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// lockWrite waits until the request is allowed to write to the repository.
+func (r *roundTripper) lockWrite(req *http.Request) (func(), error) {
+	repo := r.repositoryKey(req)
+	r.mu.Lock()
+	lock, ok := r.repoLocks[repo]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.repoLocks[repo] = lock
+	}
+	r.mu.Unlock()
+
+	// acquire the repository lock first not to occupy a slot while waiting for it
+	lock.Lock()
+	if r.writeSlots == nil {
+		return lock.Unlock, nil
+	}
+	select {
+	case r.writeSlots <- struct{}{}:
+	case <-req.Context().Done():
+		lock.Unlock()
+		return nil, req.Context().Err()
+	}
+	return func() {
+		<-r.writeSlots
+		lock.Unlock()
+	}, nil
+}
+
+// repositoryKey returns the key of the repository lock for the request, which is the repository ID if it is resolved.
+func (r *roundTripper) repositoryKey(req *http.Request) string {
+	repo := repositoryFromPath(req.URL.EscapedPath())
+	if repo == "" || r.resolveRepository == nil {
+		return repo
+	}
+	if _, err := strconv.Atoi(repo); err == nil {
+		return repo
+	}
+	// resolved by a read request, which does not take the lock, and memoized by the client
+	id, err := r.resolveRepository(req.Context(), repo)
+	if err != nil {
+		tflog.Debug(req.Context(), "failed to resolve repository for the write lock", map[string]interface{}{
+			"repository": repo,
+			"error":      err.Error(),
+		})
+		return repo
+	}
+	return strconv.Itoa(id)
+}
+
+// repositoryFromPath returns the repository ID or slug of the API path like /repo/{repository.id}/env_vars.
+// Paths not belonging to a repository share the empty key.
+func repositoryFromPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] != "repo" {
+			continue
+		}
+		repo, err := url.PathUnescape(segments[i+1])
+		if err != nil {
+			return segments[i+1]
+		}
+		return repo
+	}
+	return ""
+}
+
+// withRequestTimeout returns the request bound by the request timeout and the function to release it.
+func (r *roundTripper) withRequestTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if r.requestTimeout <= 0 {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), r.requestTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// cancelOnClose releases the request timeout when the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// withToken returns the copy of the request authorized by the token.
+func withToken(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "token "+token)
+	return clone
+}
+
+// rewindRequest returns the request with a fresh body if rewind is true.
+func rewindRequest(req *http.Request, rewind bool) *http.Request {
+	if !rewind || req.Body == nil || req.GetBody == nil {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	body, err := req.GetBody()
+	if err == nil {
+		clone.Body = body
+	}
+	return clone
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		// rate limited requests have not been processed
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header in either delay-seconds or HTTP-date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
 }
 
 func isNotFound(err error) bool {
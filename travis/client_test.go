@@ -1,3 +1,232 @@
 package travis
 
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
 var IsNotFound = isNotFound
+
+func newRetryTestClient(t *testing.T, maxRetries int, handler func(attempt int32, w http.ResponseWriter, r *http.Request)) (*http.Client, string, *int32) {
+	t.Helper()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(atomic.AddInt32(&attempts, 1), w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := newRoundTripper(http.DefaultTransport, WithRetry(maxRetries, time.Second))
+	transport.retryInitialInterval = time.Millisecond
+	return &http.Client{Transport: transport}, server.URL, &attempts
+}
+
+func TestRoundTripper_retryRateLimitedWrite(t *testing.T) {
+	client, url, attempts := newRetryTestClient(t, 3, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d: unexpected body %q", attempt, body)
+		}
+		if attempt < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	resp, err := client.Post(url, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(attempts); n != 3 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+}
+
+func TestRoundTripper_noRetryNonIdempotent(t *testing.T) {
+	client, url, attempts := newRetryTestClient(t, 3, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	resp, err := client.Post(url, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(attempts); n != 1 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+}
+
+func TestRoundTripper_exhaustRetries(t *testing.T) {
+	client, url, attempts := newRetryTestClient(t, 2, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, "unavailable")
+	})
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "unavailable" {
+		t.Errorf("unexpected body: %q", body)
+	}
+	if n := atomic.LoadInt32(attempts); n != 3 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+}
+
+func TestRoundTripper_requestTimeout(t *testing.T) {
+	client, url, attempts := newRetryTestClient(t, 1, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt == 1 || r.Method == http.MethodPost {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	})
+	client.Transport.(*roundTripper).requestTimeout = 50 * time.Millisecond
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("unexpected body: %q", body)
+	}
+	if n := atomic.LoadInt32(attempts); n != 2 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+
+	if _, err := client.Post(url, "text/plain", strings.NewReader("payload")); err == nil {
+		t.Error("expected a timeout error")
+	}
+	if n := atomic.LoadInt32(attempts); n != 3 {
+		t.Errorf("timed out writes must not be retried, but attempted %d times", n-2)
+	}
+}
+
+func TestRoundTripper_lockWrite(t *testing.T) {
+	arrived := make(chan string)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- r.URL.EscapedPath()
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := newRoundTripper(http.DefaultTransport, WithMaxParallelWrites(2))
+	transport.resolveRepository = func(_ context.Context, slug string) (int, error) {
+		if slug == "owner/name" {
+			return 1, nil
+		}
+		return 0, errors.New("not found")
+	}
+	client := &http.Client{Transport: transport}
+	var wg sync.WaitGroup
+	write := func(repo string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Post(server.URL+"/repo/"+repo+"/env_vars", "application/json", strings.NewReader("{}"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	// a blocked write never arrives, so this only waits long enough for an unblocked one to do so
+	expectBlocked := func(msg string) {
+		t.Helper()
+		select {
+		case path := <-arrived:
+			t.Fatalf("%s, but %s arrived", msg, path)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// the same repository addressed by the ID and the slug
+	write("1")
+	<-arrived
+	write("owner%2Fname")
+	expectBlocked("writes to the same repository must be serialized")
+	release <- struct{}{}
+	<-arrived
+	release <- struct{}{}
+
+	// different repositories
+	write("2")
+	write("3")
+	write("4")
+	<-arrived
+	<-arrived
+	expectBlocked("expected 2 writes in flight at most")
+	release <- struct{}{}
+	<-arrived
+	release <- struct{}{}
+	release <- struct{}{}
+	wg.Wait()
+}
+
+func TestRepositoryFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/repo/1234/env_vars":             "1234",
+		"/repo/owner%2Fname/key_pair":     "owner/name",
+		"/api/repo/1234/branch/main/cron": "1234",
+		"/repo/1234":                      "1234",
+		"/cron/1":                         "",
+		"/user/1/sync":                    "",
+	} {
+		if got := repositoryFromPath(path); got != want {
+			t.Errorf("repositoryFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "3", want: 3 * time.Second, ok: true},
+		{header: "-1", ok: false},
+		{header: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, ok: true},
+		{header: "soon", ok: false},
+	} {
+		resp := &http.Response{Header: http.Header{}}
+		if tc.header != "" {
+			resp.Header.Set("Retry-After", tc.header)
+		}
+		got, ok := retryAfter(resp)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("retryAfter(%q) = (%v, %v), want (%v, %v)", tc.header, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package travis
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func dataSourceEnvVars() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to list environment variables of a repository.",
+
+		ReadContext: dataSourceEnvVarsRead,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Value uniquely identifying the repository.",
+				ExactlyOneOf: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Same as {repository.owner.name}/{repository.name}.",
+				ExactlyOneOf: []string{"repository_id"},
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Filters environment variables by a regular expression matched against the name.",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"branch": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Filters environment variables by the branch. Variables for all branches are not included.",
+			},
+
+			"env_vars": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Environment variables matching the filters, sorted by the name and the branch. `value` is empty for private variables since the API does not return them.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"branch": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceEnvVarsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	repo, err := newRepositoryEnvVars(client, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	envVars, err := repo.list(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return diag.Errorf("%s is not found or not visible to the token", repo)
+		}
+		return diag.FromErr(err)
+	}
+
+	var nameRe *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRe = regexp.MustCompile(v.(string))
+	}
+	branch, filterBranch := d.GetOk("branch")
+
+	matched := make([]*travis.EnvVar, 0, len(envVars))
+	for _, envVar := range envVars {
+		key := envVarKeyOfAPI(envVar)
+		if nameRe != nil && !nameRe.MatchString(key.name) {
+			continue
+		}
+		if filterBranch && key.branch != branch.(string) {
+			continue
+		}
+		matched = append(matched, envVar)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := envVarKeyOfAPI(matched[i]), envVarKeyOfAPI(matched[j])
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.branch < b.branch
+	})
+
+	list := make([]map[string]interface{}, 0, len(matched))
+	for _, envVar := range matched {
+		v := flattenEnvVar(envVar, nil)
+		if envVar.Id != nil {
+			v["id"] = *envVar.Id
+		}
+		list = append(list, v)
+	}
+
+	setRepositoryResourceID(d)
+	if err := d.Set("env_vars", list); err != nil {
+		return diag.Errorf("failed to set env_vars: %v", err)
+	}
+	return diags
+}
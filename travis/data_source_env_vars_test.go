@@ -0,0 +1,65 @@
+package travis_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func TestDataSourceEnvVars_fakeAPI(t *testing.T) {
+	api := newFakeAPI(t)
+	for _, body := range []*travis.EnvVarBody{
+		{Name: "DEPLOY_TARGET", Value: "production", Public: true, Branch: "main"},
+		{Name: "DEPLOY_TARGET", Value: "staging", Public: true},
+		{Name: "DEPLOY_TOKEN", Value: "secret"},
+		{Name: "OTHER", Value: "other", Public: true},
+	} {
+		api.createEnvVar(body)
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + fmt.Sprintf(`
+data "travis_env_vars" "all" {
+	repository_slug = %q
+}
+
+data "travis_env_vars" "deploy" {
+	repository_id = %d
+	name_regex    = "^DEPLOY_"
+}
+
+data "travis_env_vars" "main" {
+	repository_slug = %q
+	branch          = "main"
+}
+`, fakeRepoSlug, fakeRepoID, fakeRepoSlug),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_env_vars.all", "id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("data.travis_env_vars.all", "repository_id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("data.travis_env_vars.all", "env_vars.#", "4"),
+
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "repository_slug", fakeRepoSlug),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.#", "3"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.0.name", "DEPLOY_TARGET"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.0.branch", ""),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.0.value", "staging"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.1.branch", "main"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.1.value", "production"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.2.name", "DEPLOY_TOKEN"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.2.public", "false"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.deploy", "env_vars.2.value", ""),
+					resource.TestCheckResourceAttrSet("data.travis_env_vars.deploy", "env_vars.2.id"),
+
+					resource.TestCheckResourceAttr("data.travis_env_vars.main", "env_vars.#", "1"),
+					resource.TestCheckResourceAttr("data.travis_env_vars.main", "env_vars.0.value", "production"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,197 @@
+package travis
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/shuheiktgw/go-travis"
+)
+
+const repositoriesPageSize = 100
+
+func dataSourceRepositories() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to list repositories of the current user or the specified owner.",
+
+		ReadContext: dataSourceRepositoriesRead,
+
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Login of the GitHub user or organization. If not set, list repositories of the current user.",
+			},
+			"active": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Filters repositories by whether or not the repository is currently enabled on Travis CI.",
+			},
+			"private": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Filters repositories by whether or not the repository is private.",
+			},
+			"starred": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Filters repositories by whether or not the repository is starred.",
+			},
+			"slug_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Filters repositories by a regular expression matched against the slug.",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"repositories": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Repositories matching the filters.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"slug": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"owner": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_branch": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"private": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"starred": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRepositoriesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var (
+		client  = m.(*Client)
+		config  = d.GetRawConfig()
+		owner   = d.Get("owner").(string)
+		filters = map[string]bool{}
+		slugRe  *regexp.Regexp
+	)
+
+	for _, key := range []string{"active", "private", "starred"} {
+		if !config.GetAttr(key).IsNull() {
+			filters[key] = d.Get(key).(bool)
+		}
+	}
+	if v, ok := d.GetOk("slug_regex"); ok {
+		slugRe = regexp.MustCompile(v.(string))
+	}
+
+	// The API can only filter by true, so false filters are applied after listing.
+	list, err := client.listRepositories(ctx, owner, filters)
+	if err != nil {
+		return diag.Errorf("failed to list repositories: %v", err)
+	}
+	repos := []map[string]interface{}{}
+	for _, repo := range list {
+		if !matchRepository(repo, filters, slugRe) {
+			continue
+		}
+		repos = append(repos, flattenRepository(repo))
+	}
+
+	d.SetId(repositoriesID(owner, filters, d.Get("slug_regex").(string)))
+	if err := d.Set("repositories", repos); err != nil {
+		return diag.Errorf("failed to set repositories: %v", err)
+	}
+	return nil
+}
+
+// repositoriesID returns the ID of the data source, which is the owner or "current" followed by the filters as a query string.
+func repositoriesID(owner string, filters map[string]bool, slugRegex string) string {
+	id := owner
+	if id == "" {
+		id = "current"
+	}
+	params := url.Values{}
+	for key, v := range filters {
+		params.Set(key, strconv.FormatBool(v))
+	}
+	if slugRegex != "" {
+		params.Set("slug_regex", slugRegex)
+	}
+	if len(params) == 0 {
+		return id
+	}
+	return id + "?" + params.Encode()
+}
+
+func matchRepository(repo *travis.Repository, filters map[string]bool, slugRe *regexp.Regexp) bool {
+	attrs := map[string]*bool{
+		"active":  repo.Active,
+		"private": repo.Private,
+		"starred": repo.Starred,
+	}
+	for key, want := range filters {
+		if v := attrs[key]; v == nil || *v != want {
+			return false
+		}
+	}
+	if slugRe != nil && (repo.Slug == nil || !slugRe.MatchString(*repo.Slug)) {
+		return false
+	}
+	return true
+}
+
+func flattenRepository(repo *travis.Repository) map[string]interface{} {
+	r := map[string]interface{}{}
+	if repo.Id != nil {
+		r["id"] = int(*repo.Id)
+	}
+	if repo.Name != nil {
+		r["name"] = *repo.Name
+	}
+	if repo.Slug != nil {
+		r["slug"] = *repo.Slug
+	}
+	if repo.Owner != nil && repo.Owner.Login != nil {
+		r["owner"] = *repo.Owner.Login
+	}
+	if repo.DefaultBranch != nil && repo.DefaultBranch.Name != nil {
+		r["default_branch"] = *repo.DefaultBranch.Name
+	}
+	if repo.Active != nil {
+		r["active"] = *repo.Active
+	}
+	if repo.Private != nil {
+		r["private"] = *repo.Private
+	}
+	if repo.Starred != nil {
+		r["starred"] = *repo.Starred
+	}
+	return r
+}
@@ -0,0 +1,61 @@
+package travis_test
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceRepositories_current(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccRepositoryIDPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "travis_repositories" "current" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_repositories.current", "id", "current"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.travis_repositories.current", "repositories.*", map[string]string{
+						"id":   testRepoID,
+						"slug": testRepoSlug,
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRepositories_byOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`data "travis_repositories" "by_owner" {
+					owner      = %q
+					active     = true
+					slug_regex = %q
+				}`, testUserLogin, "^"+regexp.QuoteMeta(testRepoSlug)+"$"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_repositories.by_owner", "id", testUserLogin+"?active=true&slug_regex="+url.QueryEscape("^"+regexp.QuoteMeta(testRepoSlug)+"$")),
+					resource.TestCheckResourceAttr("data.travis_repositories.by_owner", "repositories.#", "1"),
+					resource.TestCheckResourceAttr("data.travis_repositories.by_owner", "repositories.0.slug", testRepoSlug),
+					resource.TestCheckResourceAttr("data.travis_repositories.by_owner", "repositories.0.active", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`data "travis_repositories" "by_owner" {
+					owner      = %q
+					active     = false
+					slug_regex = %q
+				}`, testUserLogin, "^"+regexp.QuoteMeta(testRepoSlug)+"$"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_repositories.by_owner", "repositories.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package travis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func dataSourceRepository() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to get the repository resource.",
+
+		ReadContext: dataSourceRepositoryRead,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Value uniquely identifying the repository.",
+				ExactlyOneOf: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Same as {repository.owner.name}/{repository.name}.",
+				ExactlyOneOf: []string{"repository_id"},
+			},
+
+			"slug": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Same as {repository.owner.name}/{repository.name}.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The repository's name on GitHub.",
+			},
+			"owner": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Login of the GitHub user or organization owning the repository.",
+			},
+			"default_branch": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The default branch on GitHub.",
+			},
+			"active": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is currently enabled on Travis CI.",
+			},
+			"private": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is private.",
+			},
+			"starred": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is starred.",
+			},
+			"github_language": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The main programming language used according to GitHub.",
+			},
+			"managed_by_installation": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is managed by a GitHub App installation.",
+			},
+		},
+	}
+}
+
+func dataSourceRepositoryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	repo, err := repositoryIdentifier(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	repository, _, err := client.Repositories.Find(ctx, repo, nil)
+	if err != nil {
+		if isNotFound(err) {
+			return diag.Errorf("repo (%s) is not found or not visible to the token", repo)
+		}
+		return diag.Errorf("failed to get repo (%s): %v", repo, err)
+	}
+	client.rememberRepository(repository)
+	if err := assignRepository(repository, d); err != nil {
+		return diag.Errorf("failed to set repository: %v", err)
+	}
+	return nil
+}
+
+func assignRepository(repository *travis.Repository, d *schema.ResourceData) error {
+	if repository.Id != nil {
+		d.SetId(strconv.FormatUint(uint64(*repository.Id), 10))
+		if err := d.Set("repository_id", int(*repository.Id)); err != nil {
+			return err
+		}
+	}
+	if repository.Slug != nil {
+		if err := d.Set("repository_slug", *repository.Slug); err != nil {
+			return err
+		}
+		if err := d.Set("slug", *repository.Slug); err != nil {
+			return err
+		}
+	}
+	if repository.Name != nil {
+		if err := d.Set("name", *repository.Name); err != nil {
+			return err
+		}
+	}
+	if repository.Owner != nil && repository.Owner.Login != nil {
+		if err := d.Set("owner", *repository.Owner.Login); err != nil {
+			return err
+		}
+	}
+	if repository.DefaultBranch != nil && repository.DefaultBranch.Name != nil {
+		if err := d.Set("default_branch", *repository.DefaultBranch.Name); err != nil {
+			return err
+		}
+	}
+	if repository.Active != nil {
+		if err := d.Set("active", *repository.Active); err != nil {
+			return err
+		}
+	}
+	if repository.Private != nil {
+		if err := d.Set("private", *repository.Private); err != nil {
+			return err
+		}
+	}
+	if repository.Starred != nil {
+		if err := d.Set("starred", *repository.Starred); err != nil {
+			return err
+		}
+	}
+	if repository.GitHubLanguage != nil {
+		if err := d.Set("github_language", *repository.GitHubLanguage); err != nil {
+			return err
+		}
+	}
+	if repository.ManagedByInstallation != nil {
+		if err := d.Set("managed_by_installation", *repository.ManagedByInstallation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
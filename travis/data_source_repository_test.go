@@ -0,0 +1,64 @@
+package travis_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceRepository_bySlug(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccRepositoryIDPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "travis_repository" "by_slug" {
+					repository_slug = "` + testRepoSlug + `"
+				}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_repository.by_slug", "id", testRepoID),
+					resource.TestCheckResourceAttr("data.travis_repository.by_slug", "repository_id", testRepoID),
+					resource.TestCheckResourceAttr("data.travis_repository.by_slug", "slug", testRepoSlug),
+					resource.TestCheckResourceAttr("data.travis_repository.by_slug", "active", "true"),
+					resource.TestCheckResourceAttrSet("data.travis_repository.by_slug", "owner"),
+					resource.TestCheckResourceAttrSet("data.travis_repository.by_slug", "default_branch"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRepository_byID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccRepositoryIDPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "travis_repository" "by_id" {
+					repository_id = ` + testRepoID + `
+				}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.travis_repository.by_id", "id", testRepoID),
+					resource.TestCheckResourceAttr("data.travis_repository.by_id", "repository_slug", testRepoSlug),
+					resource.TestCheckResourceAttr("data.travis_repository.by_id", "slug", testRepoSlug),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRepository_notFound(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "travis_repository" "not_found" {
+					repository_slug = "` + testUserLogin + `/not-found-repository"
+				}`,
+				ExpectError: regexp.MustCompile("is not found or not visible to the token"),
+			},
+		},
+	})
+}
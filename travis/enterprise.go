@@ -0,0 +1,118 @@
+package travis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// requiredAPIResources maps the resources and data sources of the provider to the API resource types they depend on.
+var requiredAPIResources = map[string]string{
+	"travis_env_var":               "env_var",
+	"travis_env_vars":              "env_vars",
+	"travis_key_pair":              "key_pair",
+	"travis_generated_key_pair":    "key_pair_generated",
+	"travis_cron":                  "cron",
+	"travis_repository_settings":   "setting",
+	"travis_repository_activation": "repository",
+	"travis_user":                  "user",
+	"travis_repository":            "repository",
+	"travis_repositories":          "repositories",
+}
+
+// enterpriseAPIURL returns the API URL of the Travis CI Enterprise served at the hostname.
+func enterpriseAPIURL(hostname string) string {
+	hostname = strings.TrimPrefix(hostname, "https://")
+	hostname = strings.TrimPrefix(hostname, "http://")
+	return "https://" + strings.TrimSuffix(hostname, "/") + "/api/"
+}
+
+// home is the API resource returned by the root of the API.
+type home struct {
+	Resources map[string]interface{} `json:"resources"`
+}
+
+// probeAPI fetches the resource types the API provides.
+// Until it is called, all resource types are considered to be supported.
+func (c *Client) probeAPI(ctx context.Context) error {
+	req, err := c.NewRequest(http.MethodGet, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	var h home
+	if _, err := c.Do(ctx, req, &h); err != nil {
+		return err
+	}
+	if len(h.Resources) == 0 {
+		return fmt.Errorf("%s does not look like Travis CI API v3", c.BaseURL)
+	}
+
+	c.apiResources = make(map[string]bool, len(h.Resources))
+	types := make([]string, 0, len(h.Resources))
+	for t := range h.Resources {
+		c.apiResources[t] = true
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	tflog.Debug(ctx, "probed travis API", map[string]interface{}{
+		"url":       c.BaseURL.String(),
+		"resources": types,
+	})
+	return nil
+}
+
+// supportsAPIResource reports whether the API provides the resource type.
+func (c *Client) supportsAPIResource(apiResource string) bool {
+	return c.apiResources == nil || c.apiResources[apiResource]
+}
+
+// requireAPIResource makes the operations of r fail when the API does not provide apiResource.
+func requireAPIResource(name, apiResource string, r *schema.Resource) *schema.Resource {
+	if apiResource == "" {
+		return r
+	}
+	check := func(m interface{}) diag.Diagnostics {
+		client := m.(*Client)
+		if client.supportsAPIResource(apiResource) {
+			return nil
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s is not supported by this Travis CI Enterprise", name),
+			Detail: fmt.Sprintf("The API at %s does not provide the %s resource. "+
+				"Upgrade Travis CI Enterprise or remove %s from the configuration.", client.BaseURL, apiResource, name),
+		}}
+	}
+	wrap := func(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if diags := check(m); diags.HasError() {
+				return diags
+			}
+			return f(ctx, d, m)
+		}
+	}
+
+	r.CreateContext = wrap(r.CreateContext)
+	r.ReadContext = wrap(r.ReadContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importState := r.Importer.StateContext
+		r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+			if diags := check(m); diags.HasError() {
+				return nil, fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
+			}
+			return importState(ctx, d, m)
+		}
+	}
+	return r
+}
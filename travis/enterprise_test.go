@@ -0,0 +1,106 @@
+package travis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func newEnterpriseTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"@type": "home",
+				"config": {"host": "travis.example.com"},
+				"resources": {"env_var": {}, "env_vars": {}, "repository": {}, "user": {}}
+			}`))
+		case "/api/user":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"@type": "user", "id": 1, "login": "enterprise-user"}`))
+		case "/api/repo/1234/env_vars":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"@type": "env_vars", "env_vars": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"@type": "error", "error_type": "not_found", "error_message": "resource not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEnterpriseAPIURL(t *testing.T) {
+	for hostname, want := range map[string]string{
+		"travis.example.com":          "https://travis.example.com/api/",
+		"https://travis.example.com/": "https://travis.example.com/api/",
+		"travis.example.com:8443":     "https://travis.example.com:8443/api/",
+	} {
+		if got := enterpriseAPIURL(hostname); got != want {
+			t.Errorf("enterpriseAPIURL(%q) = %q, want %q", hostname, got, want)
+		}
+	}
+}
+
+func TestProvider_enterprise(t *testing.T) {
+	server := newEnterpriseTestServer(t)
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"hostname":             strings.TrimPrefix(server.URL, "https://"),
+		"insecure_skip_verify": true,
+		"token":                "fake",
+	}))
+	if diags.HasError() {
+		t.Fatalf("failed to configure: %v", diags)
+	}
+	client := p.Meta().(*Client)
+
+	if !client.supportsAPIResource("env_var") {
+		t.Error("env_var must be supported")
+	}
+	if client.supportsAPIResource("key_pair") {
+		t.Error("key_pair must not be supported")
+	}
+
+	keyPair := p.ResourcesMap["travis_key_pair"]
+	d := keyPair.TestResourceData()
+	d.SetId("1234")
+	diags = keyPair.ReadContext(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "travis_key_pair is not supported") {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestProvider_enterpriseUnreachable(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"hostname":             strings.TrimPrefix(server.URL, "https://"),
+		"insecure_skip_verify": true,
+		"max_retries":          0,
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error for the server not serving the API")
+	}
+}
+
+func TestRequiredAPIResources(t *testing.T) {
+	p := Provider()
+	for _, m := range []map[string]*schema.Resource{p.ResourcesMap, p.DataSourcesMap} {
+		for name := range m {
+			if _, ok := requiredAPIResources[name]; !ok {
+				t.Errorf("%s is missing in requiredAPIResources", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package travis_test
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shuheiktgw/go-travis"
+)
+
+const (
+	fakeRepoID   = 1234
+	fakeRepoSlug = "fake-owner/fake-repo"
+)
+
+// fakeAPI is an in-memory Travis CI API serving a single repository.
+// The repository can be addressed by both fakeRepoID and its slug, which is fakeRepoSlug until renamed.
+// The tests against it run the Terraform CLI with resource.Test, so they run only with TF_ACC like the acceptance tests,
+// but they need no credentials.
+type fakeAPI struct {
+	*httptest.Server
+
+	mu               sync.Mutex
+	slug             string
+	requests         []string
+	keyPair          *travis.KeyPair
+	generatedKeyPair *travis.KeyPair
+	generatedCount   int
+	crons            map[uint]*travis.Cron
+	lastCronID       uint
+	envVars          map[string]*travis.EnvVar
+	lastEnvVarID     int
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+
+	f := &fakeAPI{slug: fakeRepoSlug, crons: map[uint]*travis.Cron{}, envVars: map[string]*travis.EnvVar{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repo/", f.handleRepo)
+	mux.HandleFunc("/cron/", f.handleCron)
+	mux.HandleFunc("/user", f.handleUser)
+	f.Server = httptest.NewServer(f.logRequests(mux))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// rename renames the repository, after which the old slug is not found like GitHub.
+func (f *fakeAPI) rename(slug string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.slug = slug
+	for _, cron := range f.crons {
+		cron.Repository.Slug = travis.String(slug)
+	}
+}
+
+// providerConfig returns the provider block pointing to the fake API.
+// TestProvider_conflicts checks that it passes the validation of the provider configuration.
+func (f *fakeAPI) providerConfig() string {
+	return fmt.Sprintf(`
+provider "travis" {
+	api_base_url = "%s/"
+	token        = "fake"
+}
+`, f.URL)
+}
+
+// countRequests returns the number of requests with the method and the path prefix.
+func (f *fakeAPI) countRequests(method, pathPrefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, req := range f.requests {
+		if strings.HasPrefix(req, method+" "+pathPrefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeAPI) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.EscapedPath())
+		f.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *fakeAPI) handleRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	args := strings.SplitN(strings.TrimPrefix(r.URL.EscapedPath(), "/repo/"), "/", 2)
+	repo, err := url.PathUnescape(args[0])
+	if err != nil || (repo != f.slug && repo != strconv.Itoa(fakeRepoID)) {
+		writeFakeError(w, http.StatusNotFound, "not_found", "repository not found")
+		return
+	}
+	if len(args) < 2 {
+		if r.Method != http.MethodGet {
+			writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+			return
+		}
+		writeFakeJSON(w, http.StatusOK, &travis.Repository{
+			Id:     travis.Uint(fakeRepoID),
+			Slug:   travis.String(f.slug),
+			Name:   travis.String("fake-repo"),
+			Active: travis.Bool(true),
+		})
+		return
+	}
+
+	switch args[1] {
+	case "key_pair":
+		f.handleKeyPair(w, r)
+	case "key_pair/generated":
+		f.handleGeneratedKeyPair(w, r)
+	case "crons":
+		f.handleCrons(w, r)
+	case "env_vars":
+		f.handleEnvVars(w, r)
+	default:
+		if id, ok := strings.CutPrefix(args[1], "env_var/"); ok {
+			f.handleEnvVar(w, r, id)
+			return
+		}
+		if branch, ok := strings.CutPrefix(args[1], "branch/"); ok && strings.HasSuffix(branch, "/cron") {
+			f.handleBranchCron(w, r, strings.TrimSuffix(branch, "/cron"))
+			return
+		}
+		writeFakeError(w, http.StatusNotFound, "not_found", "resource not found")
+	}
+}
+
+func (f *fakeAPI) handleKeyPair(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if f.keyPair == nil {
+			writeFakeError(w, http.StatusNotFound, "not_found", "key_pair not found")
+			return
+		}
+		writeFakeJSON(w, http.StatusOK, f.keyPair)
+	case http.MethodPost:
+		if f.keyPair != nil {
+			writeFakeError(w, http.StatusConflict, "duplicate_resource", "resource already exists")
+			return
+		}
+		var body travis.KeyPairBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "wrong_params", err.Error())
+			return
+		}
+		f.keyPair = &travis.KeyPair{}
+		updateFakeKeyPair(f.keyPair, &body)
+		writeFakeJSON(w, http.StatusCreated, f.keyPair)
+	case http.MethodPatch:
+		if f.keyPair == nil {
+			writeFakeError(w, http.StatusNotFound, "not_found", "key_pair not found")
+			return
+		}
+		var body travis.KeyPairBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "wrong_params", err.Error())
+			return
+		}
+		updateFakeKeyPair(f.keyPair, &body)
+		writeFakeJSON(w, http.StatusOK, f.keyPair)
+	case http.MethodDelete:
+		if f.keyPair == nil {
+			writeFakeError(w, http.StatusNotFound, "not_found", "key_pair not found")
+			return
+		}
+		f.keyPair = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+func (f *fakeAPI) handleGeneratedKeyPair(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if f.generatedKeyPair == nil {
+			writeFakeError(w, http.StatusNotFound, "not_found", "key_pair not found")
+			return
+		}
+		writeFakeJSON(w, http.StatusOK, f.generatedKeyPair)
+	case http.MethodPost:
+		f.generatedCount++
+		f.generatedKeyPair = &travis.KeyPair{Description: travis.String("generated")}
+		updateFakeKeyPair(f.generatedKeyPair, &travis.KeyPairBody{Value: fmt.Sprintf("generated private key %d", f.generatedCount)})
+		writeFakeJSON(w, http.StatusCreated, f.generatedKeyPair)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+func (f *fakeAPI) handleCrons(w http.ResponseWriter, r *http.Request) {
+	crons := make([]*travis.Cron, 0, len(f.crons))
+	for _, cron := range f.crons {
+		crons = append(crons, cron)
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{"crons": crons})
+}
+
+func (f *fakeAPI) handleBranchCron(w http.ResponseWriter, r *http.Request, branch string) {
+	switch r.Method {
+	case http.MethodGet:
+		for _, cron := range f.crons {
+			if *cron.Branch.Name == branch {
+				writeFakeJSON(w, http.StatusOK, cron)
+				return
+			}
+		}
+		writeFakeError(w, http.StatusNotFound, "not_found", "cron not found")
+	case http.MethodPost:
+		var body travis.CronBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "wrong_params", err.Error())
+			return
+		}
+		cron := f.createCron(branch, &body)
+		writeFakeJSON(w, http.StatusCreated, cron)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+// createCron must be called with f.mu held.
+func (f *fakeAPI) createCron(branch string, body *travis.CronBody) *travis.Cron {
+	// same as Travis CI, creating a cron replaces the existing one of the branch
+	for id, cron := range f.crons {
+		if *cron.Branch.Name == branch {
+			delete(f.crons, id)
+		}
+	}
+	f.lastCronID++
+	cron := &travis.Cron{
+		Id:                         travis.Uint(f.lastCronID),
+		Repository:                 &travis.Repository{Id: travis.Uint(fakeRepoID), Slug: travis.String(f.slug)},
+		Branch:                     &travis.Branch{Name: travis.String(branch)},
+		Interval:                   travis.String(body.Interval),
+		DontRunIfRecentBuildExists: travis.Bool(body.DontRunIfRecentBuildExists),
+		NextRun:                    travis.String(fmt.Sprintf("2020-01-%02dT00:00:00Z", f.lastCronID)),
+		CreatedAt:                  travis.String(fmt.Sprintf("2019-12-%02dT00:00:00Z", f.lastCronID)),
+		Active:                     travis.Bool(true),
+	}
+	f.crons[f.lastCronID] = cron
+	return cron
+}
+
+func (f *fakeAPI) handleCron(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/cron/"), 10, 64)
+	cron, ok := f.crons[uint(id)]
+	if err != nil || !ok {
+		writeFakeError(w, http.StatusNotFound, "not_found", "cron not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, cron)
+	case http.MethodDelete:
+		delete(f.crons, uint(id))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+func (f *fakeAPI) handleEnvVars(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		envVars := make([]*travis.EnvVar, 0, len(f.envVars))
+		for _, envVar := range f.envVars {
+			envVars = append(envVars, fakeEnvVarRepresentation(envVar))
+		}
+		sort.Slice(envVars, func(i, j int) bool { return *envVars[i].Id < *envVars[j].Id })
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"env_vars": envVars})
+	case http.MethodPost:
+		var body travis.EnvVarBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "wrong_params", err.Error())
+			return
+		}
+		writeFakeJSON(w, http.StatusCreated, fakeEnvVarRepresentation(f.createEnvVar(&body)))
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+// createEnvVar stores the env var, which is used also to prepare variables unknown to Terraform.
+func (f *fakeAPI) createEnvVar(body *travis.EnvVarBody) *travis.EnvVar {
+	f.lastEnvVarID++
+	envVar := &travis.EnvVar{
+		Id:     travis.String(fmt.Sprintf("env-var-%03d", f.lastEnvVarID)),
+		Name:   travis.String(body.Name),
+		Value:  travis.String(body.Value),
+		Public: travis.Bool(body.Public),
+	}
+	if body.Branch != "" {
+		envVar.Branch = travis.String(body.Branch)
+	}
+	f.envVars[*envVar.Id] = envVar
+	return envVar
+}
+
+func (f *fakeAPI) handleEnvVar(w http.ResponseWriter, r *http.Request, id string) {
+	envVar, ok := f.envVars[id]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "not_found", "env_var not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, fakeEnvVarRepresentation(envVar))
+	case http.MethodPatch:
+		var body travis.EnvVarBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "wrong_params", err.Error())
+			return
+		}
+		// same as Travis CI, empty fields are ignored
+		if body.Name != "" {
+			envVar.Name = travis.String(body.Name)
+		}
+		if body.Value != "" {
+			envVar.Value = travis.String(body.Value)
+		}
+		if body.Branch != "" {
+			envVar.Branch = travis.String(body.Branch)
+		}
+		envVar.Public = travis.Bool(body.Public)
+		writeFakeJSON(w, http.StatusOK, fakeEnvVarRepresentation(envVar))
+	case http.MethodDelete:
+		delete(f.envVars, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+	}
+}
+
+// fakeEnvVarRepresentation hides the value of the private env var like Travis CI.
+func fakeEnvVarRepresentation(envVar *travis.EnvVar) *travis.EnvVar {
+	v := *envVar
+	if !*v.Public {
+		v.Value = nil
+	}
+	return &v
+}
+
+func (f *fakeAPI) handleUser(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token fake" {
+		writeFakeError(w, http.StatusForbidden, "login_required", "login required")
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, &travis.User{Id: travis.Uint(1), Login: travis.String("fake-owner")})
+}
+
+func updateFakeKeyPair(keyPair *travis.KeyPair, body *travis.KeyPairBody) {
+	if body.Description != "" {
+		keyPair.Description = travis.String(body.Description)
+	}
+	if body.Value != "" {
+		fingerprint := fmt.Sprintf("%x", md5.Sum([]byte(body.Value)))
+		keyPair.Fingerprint = travis.String(fingerprint)
+		keyPair.PublicKey = travis.String("public key of " + fingerprint)
+	}
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, errorType, message string) {
+	writeFakeJSON(w, status, map[string]string{
+		"@type":         "error",
+		"error_type":    errorType,
+		"error_message": message,
+	})
+}
@@ -1,13 +1,22 @@
 package travis
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/shuheiktgw/go-travis"
 )
 
 // Provider returns a *schema.Provider.
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_base_url": {
 				Type:        schema.TypeString,
@@ -15,26 +24,318 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_API_BASE_URL", travis.ApiComUrl),
 				Description: "the base URL for API request",
 			},
+			"default_repository_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_DEFAULT_REPOSITORY_ID", 0),
+				Description: "the repository ID used by `travis_env_var`, `travis_env_vars`, `travis_cron` and `travis_key_pair` specifying neither `repository_id` nor `repository_slug`; conflicts with `default_repository_slug`",
+			},
+			"default_repository_slug": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_DEFAULT_REPOSITORY_SLUG", ""),
+				Description: "the repository slug used by `travis_env_var`, `travis_env_vars`, `travis_cron` and `travis_key_pair` specifying neither `repository_id` nor `repository_slug`; conflicts with `default_repository_id`",
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_SKIP_CREDENTIALS_VALIDATION", false),
+				Description: "whether to skip validating the token by getting the current user on configure, e.g. for offline plans",
+			},
+			"hostname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_HOSTNAME", ""),
+				Description: "the hostname of Travis CI Enterprise; the API URL is derived from it and the features of the server are checked on configure; conflicts with `api_base_url`",
+			},
 			"token": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_TOKEN", ""),
 				Description: "an API access token generated by the Travis CI command line client: `travis token`",
 			},
+			"token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_TOKEN_FILE", ""),
+				Description: "the path to a file containing the API access token; the file is read again when the token is rejected; conflicts with `token` and `token_command`",
+			},
+			"token_command": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    1,
+				Description: "a command and its arguments printing the API access token to stdout; the command is run again when the token is rejected; conflicts with `token` and `token_file`",
+			},
+			"github_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("GITHUB_TOKEN", ""),
+				Description: "a GitHub token exchanged for the API access token on configure; it is used only when none of `token`, `token_file` and `token_command` is set",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_MAX_RETRIES", 5),
+				Description:  "the maximum number of retries for rate limited or transient API errors; `0` disables retrying",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_parallel_writes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_MAX_PARALLEL_WRITES", 10),
+				Description:  "the maximum number of concurrent write requests; writes to the same repository are always sent one at a time",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_REQUESTS_PER_SECOND", 0.0),
+				Description:  "the maximum average number of API requests per second; `0` disables rate limiting",
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_BURST", 1),
+				Description:  "the maximum number of API requests sent at once when `requests_per_second` is set",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_REQUEST_TIMEOUT", 60),
+				Description:  "the number of seconds to wait for each API request, which is retried on timeout if possible; `0` disables the timeout",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"ca_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_CA_CERT_FILE", ""),
+				Description: "the path to PEM-encoded CA certificates trusted in addition to the system ones; conflicts with `ca_cert_pem`",
+			},
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM-encoded CA certificates trusted in addition to the system ones; conflicts with `ca_cert_file`",
+			},
+			"client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "a PEM-encoded certificate for TLS client authentication",
+				RequiredWith: []string{"client_key"},
+			},
+			"client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "a PEM-encoded private key for TLS client authentication",
+				RequiredWith: []string{"client_cert"},
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TRAVIS_INSECURE_SKIP_VERIFY", false),
+				Description: "whether to skip verifying the server certificate; this should only be used for testing",
+			},
+			"proxy_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "the URL of the proxy for API requests; if not set, `HTTPS_PROXY` and `NO_PROXY` environment variables are used",
+				ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TRAVIS_RETRY_MAX_WAIT", 30),
+				Description:  "the maximum number of seconds to wait between retries, including the wait requested by `Retry-After`",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"travis_env_var":  resourceEnvVar(),
-			"travis_key_pair": resourceKeyPair(),
-			"travis_cron":     resourceCron(),
+			"travis_env_var":               resourceEnvVar(),
+			"travis_env_vars":              resourceEnvVars(),
+			"travis_key_pair":              resourceKeyPair(),
+			"travis_generated_key_pair":    resourceGeneratedKeyPair(),
+			"travis_cron":                  resourceCron(),
+			"travis_repository_settings":   resourceRepositorySettings(),
+			"travis_repository_activation": resourceRepositoryActivation(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"travis_user": dataSourceUser(),
-		},
-		ConfigureFunc: func(d *schema.ResourceData) (interface{}, error) {
-			return NewClient(
-				d.Get("api_base_url").(string),
-				d.Get("token").(string),
-			), nil
+			"travis_user":         dataSourceUser(),
+			"travis_repository":   dataSourceRepository(),
+			"travis_repositories": dataSourceRepositories(),
+			"travis_env_vars":     dataSourceEnvVars(),
 		},
+		ConfigureContextFunc: providerConfigure,
+	}
+	for name, r := range p.ResourcesMap {
+		requireAPIResource(name, requiredAPIResources[name], r)
+	}
+	for name, r := range p.DataSourcesMap {
+		requireAPIResource(name, requiredAPIResources[name], r)
+	}
+	return p
+}
+
+// providerAttribute is an attribute of the provider which can also be set by the environment variable.
+type providerAttribute struct {
+	name string
+	env  string
+}
+
+// conflictingProviderAttributes are the groups of the provider attributes, at most one of which can be set.
+// They are checked on configure instead of ConflictsWith,
+// because the SDK fills in the defaults, including the environment variables, before checking it.
+var conflictingProviderAttributes = [][]providerAttribute{
+	{{"api_base_url", "TRAVIS_API_BASE_URL"}, {"hostname", "TRAVIS_HOSTNAME"}},
+	{{"ca_cert_file", "TRAVIS_CA_CERT_FILE"}, {"ca_cert_pem", ""}},
+	{{"token", "TRAVIS_TOKEN"}, {"token_file", "TRAVIS_TOKEN_FILE"}, {"token_command", ""}},
+	{{"default_repository_id", "TRAVIS_DEFAULT_REPOSITORY_ID"}, {"default_repository_slug", "TRAVIS_DEFAULT_REPOSITORY_SLUG"}},
+}
+
+// isProviderAttributeConfigured reports whether the attribute is set to a non-empty value in the configuration.
+func isProviderAttributeConfigured(d *schema.ResourceData, attr providerAttribute) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() {
+		return false
+	}
+	v := config.GetAttr(attr.name)
+	if !v.IsKnown() {
+		return true
+	}
+	return !v.IsNull() && !v.RawEquals(cty.StringVal("")) && !v.RawEquals(cty.Zero)
+}
+
+// resolveProviderConflicts rejects the conflicting attributes set in the configuration together,
+// or by the environment variables together.
+// An attribute set in the configuration overrides the conflicting ones set by the environment variables,
+// which are cleared.
+func resolveProviderConflicts(d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, attrs := range conflictingProviderAttributes {
+		var configured, fromEnv []string
+		for _, attr := range attrs {
+			if isProviderAttributeConfigured(d, attr) {
+				configured = append(configured, attr.name)
+			} else if attr.env != "" && os.Getenv(attr.env) != "" {
+				fromEnv = append(fromEnv, attr.name)
+			}
+		}
+		switch {
+		case len(configured) > 1:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%q: conflicts with %s", configured[0], strings.Join(configured[1:], ", ")),
+				Detail:   "Only one of them can be set in the provider block.",
+			})
+		case len(configured) == 1:
+			for _, name := range fromEnv {
+				// setting nil falls back to the default, so the zero value is set explicitly
+				var zero interface{} = ""
+				if _, ok := d.Get(name).(int); ok {
+					zero = 0
+				}
+				if err := d.Set(name, zero); err != nil {
+					diags = append(diags, diag.FromErr(err)...)
+				}
+			}
+		case len(fromEnv) > 1:
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%q: conflicts with %s", fromEnv[0], strings.Join(fromEnv[1:], ", ")),
+				Detail:   "Only one of their environment variables can be set unless one of them is set in the provider block.",
+			})
+		}
+	}
+	return diags
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	if diags := resolveProviderConflicts(d); diags.HasError() {
+		return nil, diags
+	}
+
+	transport, err := WithTransportConfig(&TransportConfig{
+		CACertFile:         d.Get("ca_cert_file").(string),
+		CACertPEM:          d.Get("ca_cert_pem").(string),
+		ClientCert:         d.Get("client_cert").(string),
+		ClientKey:          d.Get("client_key").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		ProxyURL:           d.Get("proxy_url").(string),
+	})
+	if err != nil {
+		return nil, diag.Errorf("failed to configure the HTTP transport: %v", err)
+	}
+
+	apiURL := d.Get("api_base_url").(string)
+	hostname := d.Get("hostname").(string)
+	if hostname != "" {
+		apiURL = enterpriseAPIURL(hostname)
+	}
+
+	opts := []ClientOption{
+		transport,
+		WithRetry(
+			d.Get("max_retries").(int),
+			time.Duration(d.Get("retry_max_wait").(int))*time.Second,
+		),
+		WithMaxParallelWrites(d.Get("max_parallel_writes").(int)),
+		WithRateLimit(d.Get("requests_per_second").(float64), d.Get("burst").(int)),
+		WithRequestTimeout(time.Duration(d.Get("request_timeout").(int)) * time.Second),
+	}
+
+	token := d.Get("token").(string)
+	var loadToken TokenLoader
+	if path := d.Get("token_file").(string); path != "" {
+		loadToken = TokenFromFile(path)
+	} else if v, ok := d.GetOk("token_command"); ok {
+		args := make([]string, 0, len(v.([]interface{})))
+		for _, arg := range v.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+		loadToken = TokenFromCommand(args)
+	} else if githubToken := d.Get("github_token").(string); githubToken != "" && token == "" {
+		// GITHUB_TOKEN is set in most CI jobs, so it silently gives way to the other token sources
+		// the client without token is used only for the exchange
+		loadToken = TokenFromGitHub(NewClient(apiURL, "", opts...), githubToken)
+	}
+	if loadToken != nil {
+		token, err = loadToken(ctx)
+		if err != nil {
+			return nil, diag.Errorf("failed to load token: %v", err)
+		}
+		opts = append(opts, WithTokenLoader(token, loadToken))
+	}
+
+	client := NewClient(apiURL, token, opts...)
+	client.defaultRepositoryID = d.Get("default_repository_id").(int)
+	client.defaultRepositorySlug = d.Get("default_repository_slug").(string)
+
+	if hostname != "" {
+		if err := client.probeAPI(ctx); err != nil {
+			return nil, diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("failed to connect to Travis CI Enterprise (%s)", hostname),
+				Detail:   fmt.Sprintf("The API is expected at %s: %v", apiURL, err),
+			}}
+		}
+	}
+	if !d.Get("skip_credentials_validation").(bool) {
+		if diags := validateCredentials(ctx, client, token); diags.HasError() {
+			return nil, diags
+		}
+	}
+	return client, nil
+}
+
+// resourceTimeouts returns the default timeouts of the resources supporting the timeouts block.
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(5 * time.Minute),
+		Read:   schema.DefaultTimeout(5 * time.Minute),
+		Update: schema.DefaultTimeout(5 * time.Minute),
+		Delete: schema.DefaultTimeout(5 * time.Minute),
 	}
 }
@@ -1,11 +1,23 @@
 package travis_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/bgpat/terraform-provider-travis/travis"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -13,6 +25,7 @@ var (
 	testAccProviders map[string]*schema.Provider
 	testAccProvider  *schema.Provider
 
+	testRepoID    = os.Getenv("TRAVIS_REPO_ID")
 	testRepoSlug  = os.Getenv("TRAVIS_REPO_SLUG")
 	testBranch    = os.Getenv("TRAVIS_BRANCH")
 	testUserID    = os.Getenv("TRAVIS_USER_ID")
@@ -38,6 +51,179 @@ func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = travis.Provider()
 }
 
+func TestProvider_conflicts(t *testing.T) {
+	for _, env := range []string{
+		"TRAVIS_API_BASE_URL",
+		"TRAVIS_HOSTNAME",
+		"TRAVIS_TOKEN",
+		"TRAVIS_TOKEN_FILE",
+		"GITHUB_TOKEN",
+		"TRAVIS_DEFAULT_REPOSITORY_ID",
+		"TRAVIS_DEFAULT_REPOSITORY_SLUG",
+		"TRAVIS_CA_CERT_FILE",
+	} {
+		t.Setenv(env, "")
+	}
+	enterprise := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"@type": "home", "resources": {"env_var": {}}}`))
+	}))
+	t.Cleanup(enterprise.Close)
+	hostname := cty.StringVal(strings.TrimPrefix(enterprise.URL, "https://"))
+	fake := newFakeAPI(t)
+
+	for name, tc := range map[string]struct {
+		config map[string]cty.Value
+		env    map[string]string
+		err    string
+	}{
+		"empty": {},
+		"fake API": {
+			// same as fakeAPI.providerConfig used by the tests against the fake API
+			config: map[string]cty.Value{
+				"api_base_url":                cty.StringVal(fake.URL + "/"),
+				"token":                       cty.StringVal("fake"),
+				"skip_credentials_validation": cty.False,
+			},
+		},
+		"hostname": {
+			config: map[string]cty.Value{"hostname": hostname, "insecure_skip_verify": cty.True},
+		},
+		"hostname and api_base_url": {
+			config: map[string]cty.Value{"hostname": hostname, "api_base_url": cty.StringVal("https://api.travis-ci.com/")},
+			err:    `"api_base_url": conflicts with hostname`,
+		},
+		"hostname and TRAVIS_API_BASE_URL": {
+			config: map[string]cty.Value{"hostname": hostname, "insecure_skip_verify": cty.True},
+			env:    map[string]string{"TRAVIS_API_BASE_URL": "https://api.travis-ci.com/"},
+		},
+		"api_base_url and TRAVIS_HOSTNAME": {
+			// the hostname would be probed and fail to connect
+			config: map[string]cty.Value{"api_base_url": cty.StringVal(fake.URL + "/")},
+			env:    map[string]string{"TRAVIS_HOSTNAME": "travis.invalid"},
+		},
+		"TRAVIS_API_BASE_URL and TRAVIS_HOSTNAME": {
+			env: map[string]string{"TRAVIS_API_BASE_URL": "https://api.travis-ci.com/", "TRAVIS_HOSTNAME": "travis.invalid"},
+			err: `"api_base_url": conflicts with hostname`,
+		},
+		"empty hostname": {
+			config: map[string]cty.Value{"hostname": cty.StringVal(""), "api_base_url": cty.StringVal("https://api.travis-ci.com/")},
+		},
+		"token_file": {
+			config: map[string]cty.Value{"token_file": cty.StringVal("/dev/null")},
+			err:    "failed to load token",
+		},
+		"token and token_command": {
+			config: map[string]cty.Value{
+				"token":         cty.StringVal("token"),
+				"token_command": cty.ListVal([]cty.Value{cty.StringVal("echo")}),
+			},
+			err: `"token": conflicts with token_command`,
+		},
+		"TRAVIS_TOKEN and token_file": {
+			// the empty file is read instead of the environment variable
+			config: map[string]cty.Value{"token_file": cty.StringVal("/dev/null")},
+			env:    map[string]string{"TRAVIS_TOKEN": "token"},
+			err:    "failed to load token",
+		},
+		"TRAVIS_TOKEN_FILE and token_command": {
+			config: map[string]cty.Value{
+				"token_command": cty.ListVal([]cty.Value{cty.StringVal("echo"), cty.StringVal("token")}),
+			},
+			env: map[string]string{"TRAVIS_TOKEN_FILE": "/dev/null"},
+		},
+		"TRAVIS_TOKEN and TRAVIS_TOKEN_FILE": {
+			env: map[string]string{"TRAVIS_TOKEN": "token", "TRAVIS_TOKEN_FILE": "/dev/null"},
+			err: `"token": conflicts with token_file`,
+		},
+		"token and GITHUB_TOKEN": {
+			config: map[string]cty.Value{"token": cty.StringVal("token")},
+			env:    map[string]string{"GITHUB_TOKEN": "github-token"},
+		},
+		"default_repository_slug": {
+			config: map[string]cty.Value{"default_repository_slug": cty.StringVal("owner/repo")},
+		},
+		"default_repository_id and default_repository_slug": {
+			config: map[string]cty.Value{
+				"default_repository_id":   cty.NumberIntVal(1234),
+				"default_repository_slug": cty.StringVal("owner/repo"),
+			},
+			err: `"default_repository_id": conflicts with default_repository_slug`,
+		},
+		"TRAVIS_DEFAULT_REPOSITORY_ID and default_repository_slug": {
+			config: map[string]cty.Value{"default_repository_slug": cty.StringVal("owner/repo")},
+			env:    map[string]string{"TRAVIS_DEFAULT_REPOSITORY_ID": "1234"},
+		},
+		"ca_cert_pem": {
+			config: map[string]cty.Value{"ca_cert_pem": cty.StringVal("invalid")},
+			err:    "failed to configure the HTTP transport",
+		},
+		"ca_cert_file and ca_cert_pem": {
+			config: map[string]cty.Value{"ca_cert_file": cty.StringVal("/dev/null"), "ca_cert_pem": cty.StringVal("invalid")},
+			err:    `"ca_cert_file": conflicts with ca_cert_pem`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			err := configureProvider(t, tc.config)
+			switch {
+			case tc.err == "" && err != "":
+				t.Errorf("unexpected error: %s", err)
+			case tc.err != "" && !strings.Contains(err, tc.err):
+				t.Errorf("expected an error containing %q, got %q", tc.err, err)
+			}
+		})
+	}
+}
+
+// configureProvider configures the provider through the plugin protocol as Terraform does, and returns the error summaries.
+// Unlike Provider.Configure, the configuration is validated after the defaults are filled in, and the raw configuration is available.
+// The credentials are not validated unless skip_credentials_validation is given.
+func configureProvider(t *testing.T, attrs map[string]cty.Value) string {
+	t.Helper()
+
+	p := travis.Provider()
+	ty := schema.InternalMap(p.Schema).CoreConfigSchema().ImpliedType()
+	vals := map[string]cty.Value{"skip_credentials_validation": cty.True}
+	for name, attrTy := range ty.AttributeTypes() {
+		if v, ok := attrs[name]; ok {
+			vals[name] = v
+		} else if _, ok := vals[name]; !ok {
+			vals[name] = cty.NullVal(attrTy)
+		}
+	}
+	b, err := msgpack.Marshal(cty.ObjectVal(vals), ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tfprotov5.DynamicValue{MsgPack: b}
+
+	server := schema.NewGRPCProviderServer(p)
+	prepared, err := server.PrepareProviderConfig(context.Background(), &tfprotov5.PrepareProviderConfigRequest{Config: config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags := prepared.Diagnostics
+	if len(diags) == 0 {
+		// Terraform configures the provider with the original configuration rather than the prepared one
+		configured, err := server.ConfigureProvider(context.Background(), &tfprotov5.ConfigureProviderRequest{Config: config})
+		if err != nil {
+			t.Fatal(err)
+		}
+		diags = configured.Diagnostics
+	}
+
+	var errs []string
+	for _, d := range diags {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			errs = append(errs, d.Summary)
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
 func testAccPreCheck(t *testing.T) {
 	t.Helper()
 
@@ -57,3 +243,55 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("TRAVIS_USER_LOGIN must be set for acceptance tests")
 	}
 }
+
+// testAccRepositoryIDPreCheck is the additional pre-check of the tests comparing the repository ID.
+func testAccRepositoryIDPreCheck(t *testing.T) {
+	t.Helper()
+
+	testAccPreCheck(t)
+	if testRepoID == "" {
+		t.Fatal("TRAVIS_REPO_ID must be set for acceptance tests of the repository data sources")
+	}
+}
+
+// testSkipBelowTerraform skips the test if the Terraform CLI used by resource.Test is older than the minimum version.
+// The CLI is looked up in the same order as resource.Test, and the latest one is installed if it is not found.
+func testSkipBelowTerraform(t *testing.T, minimum string) {
+	t.Helper()
+
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		// resource.Test skips the test without running the CLI
+		return
+	}
+	current, err := testTerraformVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != nil && current.LessThan(version.Must(version.NewVersion(minimum))) {
+		t.Skipf("Terraform CLI %s is older than %s", current, minimum)
+	}
+}
+
+func testTerraformVersion() (*version.Version, error) {
+	path := os.Getenv("TF_ACC_TERRAFORM_PATH")
+	if path == "" {
+		if v := os.Getenv("TF_ACC_TERRAFORM_VERSION"); v != "" {
+			return version.NewVersion(v)
+		}
+		var err error
+		if path, err = exec.LookPath("terraform"); err != nil {
+			return nil, nil
+		}
+	}
+	out, err := exec.Command(path, "version", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the version of Terraform CLI (%s): %w", path, err)
+	}
+	var v struct {
+		TerraformVersion string `json:"terraform_version"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse the version of Terraform CLI (%s): %w", path, err)
+	}
+	return version.NewVersion(v.TerraformVersion)
+}
@@ -0,0 +1,54 @@
+package travis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_wait(t *testing.T) {
+	l := newRateLimiter(20, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 requests are sent at once and the others wait for 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("requests are not limited: %v", elapsed)
+	}
+}
+
+func TestRateLimiter_cancel(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("expected an error by the canceled context")
+	}
+	if l.tokens < -0.1 {
+		t.Errorf("the token of the canceled request is not returned: %v", l.tokens)
+	}
+}
+
+func TestRateLimiter_cancelBurst(t *testing.T) {
+	l := newRateLimiter(1, 2)
+	for i := 0; i < 3; i++ {
+		l.reserve()
+	}
+	// the bucket is refilled before the waits are canceled
+	l.mu.Lock()
+	l.tokens = l.burst
+	l.mu.Unlock()
+	for i := 0; i < 3; i++ {
+		l.cancel()
+	}
+	if l.tokens > l.burst {
+		t.Errorf("tokens exceed the burst: %v", l.tokens)
+	}
+}
@@ -0,0 +1,321 @@
+package travis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/shuheiktgw/go-travis"
+)
+
+// repositoryResolver memoizes the mapping between repository IDs and slugs.
+type repositoryResolver struct {
+	mu    sync.Mutex
+	slugs map[int]string
+	ids   map[string]int
+}
+
+func newRepositoryResolver() *repositoryResolver {
+	return &repositoryResolver{
+		slugs: map[int]string{},
+		ids:   map[string]int{},
+	}
+}
+
+func (r *repositoryResolver) remember(id int, slug string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.slugs[id] = slug
+	r.ids[slug] = id
+}
+
+func (r *repositoryResolver) lookup(id int, slug string) (int, string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id > 0 {
+		slug, ok := r.slugs[id]
+		return id, slug, ok
+	}
+	id, ok := r.ids[slug]
+	return id, slug, ok
+}
+
+// rememberRepository records the ID and the slug of the repository returned by the API.
+func (c *Client) rememberRepository(repo *travis.Repository) {
+	if repo != nil && repo.Id != nil && repo.Slug != nil {
+		c.repositories.remember(int(*repo.Id), *repo.Slug)
+	}
+}
+
+// resolveRepository returns both the ID and the slug of the repository specified by either of them.
+func (c *Client) resolveRepository(ctx context.Context, id int, slug string) (int, string, error) {
+	var repo string
+	switch {
+	case id > 0:
+		repo = strconv.Itoa(id)
+	case slug != "":
+		repo = slug
+	default:
+		return 0, "", errors.New("one of repository_id or repository_slug must be specified")
+	}
+	if id, slug, ok := c.repositories.lookup(id, slug); ok {
+		return id, slug, nil
+	}
+
+	repository, _, err := c.Repositories.Find(ctx, repo, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to resolve repo (%s): %w", repo, err)
+	}
+	if repository.Id == nil || repository.Slug == nil {
+		return 0, "", fmt.Errorf("repo (%s) has no id or slug", repo)
+	}
+	c.rememberRepository(repository)
+	return int(*repository.Id), *repository.Slug, nil
+}
+
+// repositoriesPage is a page of the repositories returned by the API.
+type repositoriesPage struct {
+	Repositories []*travis.Repository `json:"repositories"`
+	Pagination   *struct {
+		IsLast bool `json:"is_last"`
+		Next   *struct {
+			Offset int `json:"offset"`
+		} `json:"next"`
+	} `json:"@pagination"`
+}
+
+// listRepositories lists all repositories of the owner, or of the current user if owner is empty,
+// following the pagination of the API.
+// Only the true values of the filters are sent since the API cannot filter by false.
+func (c *Client) listRepositories(ctx context.Context, owner string, filters map[string]bool) ([]*travis.Repository, error) {
+	path := "repos"
+	if owner != "" {
+		path = fmt.Sprintf("owner/%s/repos", url.PathEscape(owner))
+	}
+	params := url.Values{}
+	for key, v := range filters {
+		if v {
+			params.Set(key, "true")
+		}
+	}
+	params.Set("limit", strconv.Itoa(repositoriesPageSize))
+
+	var repos []*travis.Repository
+	offset := 0
+	for {
+		params.Set("offset", strconv.Itoa(offset))
+		tflog.Debug(ctx, "list repositories", map[string]interface{}{
+			"owner":  owner,
+			"offset": offset,
+		})
+		req, err := c.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page repositoriesPage
+		if _, err := c.Do(ctx, req, &page); err != nil {
+			return nil, err
+		}
+		repos = append(repos, page.Repositories...)
+
+		switch {
+		case page.Pagination == nil:
+			// not paginated by the server
+			return repos, nil
+		case page.Pagination.IsLast || page.Pagination.Next == nil || len(page.Repositories) == 0:
+			return repos, nil
+		}
+		offset = page.Pagination.Next.Offset
+	}
+}
+
+// assignRepositoryIdentifiers records both repository_id and repository_slug of the resource.
+// The missing one is resolved by the other, and the resource is left as it is if the resolution fails.
+func assignRepositoryIdentifiers(ctx context.Context, client *Client, d *schema.ResourceData) diag.Diagnostics {
+	id, slug, err := client.resolveRepository(ctx, d.Get("repository_id").(int), d.Get("repository_slug").(string))
+	if err != nil {
+		tflog.Warn(ctx, "failed to resolve repository", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	return setRepositoryIdentifiers(d, id, slug)
+}
+
+// setRepositoryIdentifiers sets the repository ID and fills in the missing slug.
+// The slug already set is kept even if the repository has been renamed,
+// since replacing it would make the plan resolve the old slug in the configuration;
+// the rename is warned instead until the configuration follows it.
+func setRepositoryIdentifiers(d *schema.ResourceData, id int, slug string) diag.Diagnostics {
+	if err := d.Set("repository_id", id); err != nil {
+		return diag.Errorf("failed to assign repository: %v", err)
+	}
+	current := d.Get("repository_slug").(string)
+	if current == "" {
+		if err := d.Set("repository_slug", slug); err != nil {
+			return diag.Errorf("failed to assign repository: %v", err)
+		}
+		return nil
+	}
+	if current == slug {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "repository was renamed",
+		Detail:        fmt.Sprintf("Repository %d was renamed or transferred from %s to %s. If repository_slug is set in the configuration, change it to the new slug; the resource is updated in place rather than replaced.", id, current, slug),
+		AttributePath: cty.GetAttrPath("repository_slug"),
+	}}
+}
+
+// customizeRepository plans repository_id and repository_slug like customizeConfiguredRepository.
+// When neither is configured, the default repository of the provider is used.
+func customizeRepository(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	client, _ := m.(*Client)
+	if configured, err := planConfiguredRepository(ctx, d, client); configured || err != nil {
+		return err
+	}
+
+	switch {
+	case client != nil && client.defaultRepositoryID > 0:
+		if d.Id() != "" && d.Get("repository_id").(int) == client.defaultRepositoryID {
+			return planRenamedRepository(ctx, d, client)
+		}
+		if err := d.SetNew("repository_id", client.defaultRepositoryID); err != nil {
+			return err
+		}
+		return planRepositoryID(d)
+	case client != nil && client.defaultRepositorySlug != "":
+		if d.Id() != "" && d.Get("repository_slug").(string) == client.defaultRepositorySlug {
+			return nil
+		}
+		if err := d.SetNew("repository_slug", client.defaultRepositorySlug); err != nil {
+			return err
+		}
+		return planRepositorySlug(ctx, d, client)
+	default:
+		return errors.New("one of repository_id or repository_slug must be specified, or default_repository_id or default_repository_slug must be set in the provider")
+	}
+}
+
+// customizeConfiguredRepository plans repository_id and repository_slug of the resources requiring one of them,
+// which is validated by ExactlyOneOf.
+func customizeConfiguredRepository(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	client, _ := m.(*Client)
+	_, err := planConfiguredRepository(ctx, d, client)
+	return err
+}
+
+// planConfiguredRepository plans repository_id and repository_slug, one of which is configured and the other is resolved by it.
+// The resource is replaced only when it moves to another repository, so a rename or a transfer of the repository is applied in place.
+// It reports false if neither is configured.
+func planConfiguredRepository(ctx context.Context, d *schema.ResourceDiff, client *Client) (bool, error) {
+	config := d.GetRawConfig()
+	idConfigured := !config.GetAttr("repository_id").IsNull()
+	slugConfigured := !config.GetAttr("repository_slug").IsNull()
+	switch {
+	case idConfigured && slugConfigured:
+		// rejected by ConflictsWith or ExactlyOneOf
+		return true, nil
+	case idConfigured:
+		if d.HasChange("repository_id") {
+			return true, planRepositoryID(d)
+		}
+		return true, planRenamedRepository(ctx, d, client)
+	case slugConfigured:
+		if d.HasChange("repository_slug") {
+			return true, planRepositorySlug(ctx, d, client)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// planRepositoryID plans the changed repository_id, which always moves the resource to another repository.
+func planRepositoryID(d *schema.ResourceDiff) error {
+	if err := d.SetNewComputed("repository_slug"); err != nil {
+		return err
+	}
+	return forceNewRepository(d, "repository_id")
+}
+
+// planRepositorySlug plans the changed repository_slug.
+// If the slug is resolved to the repository ID in the state, the repository has been renamed or transferred.
+func planRepositorySlug(ctx context.Context, d *schema.ResourceDiff, client *Client) error {
+	if client != nil && d.NewValueKnown("repository_slug") {
+		oldSlug, newSlug := d.GetChange("repository_slug")
+		id, _, err := client.resolveRepository(ctx, 0, newSlug.(string))
+		if err == nil {
+			if d.Id() != "" && id == d.Get("repository_id").(int) {
+				tflog.Warn(ctx, "repository was renamed", map[string]interface{}{
+					"repository_id": id,
+					"old_slug":      oldSlug.(string),
+					"new_slug":      newSlug.(string),
+				})
+				return nil
+			}
+			if err := d.SetNew("repository_id", id); err != nil {
+				return err
+			}
+			return forceNewRepository(d, "repository_slug")
+		}
+		tflog.Debug(ctx, "failed to resolve repository on plan", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := d.SetNewComputed("repository_id"); err != nil {
+		return err
+	}
+	return forceNewRepository(d, "repository_slug")
+}
+
+// planRenamedRepository updates the computed repository_slug in place
+// if the repository specified by the unchanged ID has been renamed or transferred.
+func planRenamedRepository(ctx context.Context, d *schema.ResourceDiff, client *Client) error {
+	if client == nil || d.Id() == "" {
+		return nil
+	}
+	_, slug, err := client.resolveRepository(ctx, d.Get("repository_id").(int), "")
+	if err != nil {
+		tflog.Debug(ctx, "failed to resolve repository on plan", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if slug == d.Get("repository_slug").(string) {
+		return nil
+	}
+	return d.SetNew("repository_slug", slug)
+}
+
+func forceNewRepository(d *schema.ResourceDiff, key string) error {
+	if d.Id() == "" {
+		return nil
+	}
+	return d.ForceNew(key)
+}
+
+// repositoryRenamed warns that repository_slug is updated in place since the repository has been renamed or transferred.
+func repositoryRenamed(d *schema.ResourceData) diag.Diagnostics {
+	if !d.HasChange("repository_slug") || d.HasChange("repository_id") {
+		return nil
+	}
+	oldSlug, newSlug := d.GetChange("repository_slug")
+	if oldSlug.(string) == "" {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "repository was renamed",
+		Detail: fmt.Sprintf("Repository %d was renamed or transferred from %s to %s, so the resource is updated in place instead of being replaced.",
+			d.Get("repository_id").(int), oldSlug, newSlug),
+	}}
+}
@@ -0,0 +1,237 @@
+package travis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestClient_resolveRepository(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.EscapedPath() != "/repo/1234" && r.URL.EscapedPath() != "/repo/owner%2Frepo" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error_type": "not_found", "error_message": "repository not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 1234, "slug": "owner/repo"})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/", "token")
+	ctx := context.Background()
+	for _, tc := range []struct {
+		id   int
+		slug string
+	}{
+		{id: 1234},
+		{slug: "owner/repo"},
+		{id: 1234},
+	} {
+		id, slug, err := client.resolveRepository(ctx, tc.id, tc.slug)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 1234 || slug != "owner/repo" {
+			t.Errorf("unexpected repository: %d, %q", id, slug)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("repository is resolved %d times", n)
+	}
+
+	if _, _, err := client.resolveRepository(ctx, 0, "owner/missing"); err == nil {
+		t.Error("expected an error for the missing repository")
+	}
+	if _, _, err := client.resolveRepository(ctx, 0, ""); err == nil {
+		t.Error("expected an error without repository")
+	}
+}
+
+func TestClient_listRepositories(t *testing.T) {
+	const total, pageCap = 5, 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/owner/owner/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("active") != "true" || r.URL.Query().Has("private") {
+			t.Errorf("unexpected filters: %s", r.URL.RawQuery)
+		}
+		// the server caps the page size below the requested limit
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		repos := []map[string]interface{}{}
+		for id := offset + 1; id <= total && id <= offset+pageCap; id++ {
+			repos = append(repos, map[string]interface{}{"id": id, "slug": fmt.Sprintf("owner/repo%d", id)})
+		}
+		pagination := map[string]interface{}{"is_last": offset+pageCap >= total, "next": nil}
+		if offset+pageCap < total {
+			pagination["next"] = map[string]interface{}{"offset": offset + pageCap, "limit": pageCap}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"repositories": repos, "@pagination": pagination})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/", "token")
+	repos, err := client.listRepositories(context.Background(), "owner", map[string]bool{"active": true, "private": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != total {
+		t.Fatalf("unexpected repositories: %d", len(repos))
+	}
+	for i, repo := range repos {
+		if int(*repo.Id) != i+1 {
+			t.Errorf("unexpected repository at %d: %d", i, *repo.Id)
+		}
+	}
+}
+
+func TestRepositoriesID(t *testing.T) {
+	for _, tc := range []struct {
+		owner     string
+		filters   map[string]bool
+		slugRegex string
+		want      string
+	}{
+		{want: "current"},
+		{owner: "owner", want: "owner"},
+		{owner: "owner", filters: map[string]bool{"private": false, "active": true}, want: "owner?active=true&private=false"},
+		{filters: map[string]bool{"starred": true}, slugRegex: "^owner/", want: "current?slug_regex=%5Eowner%2F&starred=true"},
+	} {
+		if got := repositoriesID(tc.owner, tc.filters, tc.slugRegex); got != tc.want {
+			t.Errorf("repositoriesID(%q, %v, %q) = %q, want %q", tc.owner, tc.filters, tc.slugRegex, got, tc.want)
+		}
+	}
+}
+
+func TestCustomizeRepository_rename(t *testing.T) {
+	repos := map[string]int{"/repo/owner%2Frenamed": 1234, "/repo/owner%2Fother": 5678}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/repo/"))
+		id, ok := repos[r.URL.EscapedPath()]
+		if r.URL.EscapedPath() == "/repo/1234" {
+			id, slug, ok = 1234, "owner/renamed", true
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error_type": "not_found", "error_message": "repository not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "slug": slug})
+	}))
+	t.Cleanup(server.Close)
+
+	for name, tc := range map[string]struct {
+		config      map[string]interface{}
+		wantReplace bool
+		wantID      string
+		wantSlug    string
+	}{
+		"renamed":    {config: map[string]interface{}{"repository_slug": "owner/renamed"}, wantID: "", wantSlug: "owner/renamed"},
+		"other":      {config: map[string]interface{}{"repository_slug": "owner/other"}, wantReplace: true, wantID: "5678", wantSlug: "owner/other"},
+		"unresolved": {config: map[string]interface{}{"repository_slug": "owner/missing"}, wantReplace: true, wantID: "<computed>", wantSlug: "owner/missing"},
+		"another id": {config: map[string]interface{}{"repository_id": 5678}, wantReplace: true, wantID: "5678", wantSlug: "<computed>"},
+		// the old slug in both the configuration and the state is kept by the refresh
+		"unchanged": {config: map[string]interface{}{"repository_slug": "owner/repo"}, wantID: ""},
+		// the computed slug follows the rename of the repository
+		"same id": {config: map[string]interface{}{"repository_id": 1234}, wantID: "", wantSlug: "owner/renamed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rawConfig := map[string]cty.Value{
+				"repository_id":   cty.NullVal(cty.Number),
+				"repository_slug": cty.NullVal(cty.String),
+			}
+			if id, ok := tc.config["repository_id"]; ok {
+				rawConfig["repository_id"] = cty.NumberIntVal(int64(id.(int)))
+			}
+			if slug, ok := tc.config["repository_slug"]; ok {
+				rawConfig["repository_slug"] = cty.StringVal(slug.(string))
+			}
+			tc.config["value"] = "private key"
+			state := &terraform.InstanceState{
+				ID: "1234",
+				Attributes: map[string]string{
+					"id":              "1234",
+					"repository_id":   "1234",
+					"repository_slug": "owner/repo",
+					"value":           "private key",
+				},
+				RawConfig: cty.ObjectVal(rawConfig),
+			}
+
+			client := NewClient(server.URL+"/", "token")
+			diff, err := resourceKeyPair().Diff(context.Background(), state, terraform.NewResourceConfigRaw(tc.config), client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := diff != nil && diff.RequiresNew(); got != tc.wantReplace {
+				t.Errorf("unexpected replacement: %v", got)
+			}
+			if got := diffAttribute(diff, "repository_id"); got != tc.wantID {
+				t.Errorf("unexpected repository_id: %q", got)
+			}
+			if got := diffAttribute(diff, "repository_slug"); got != tc.wantSlug {
+				t.Errorf("unexpected repository_slug: %q", got)
+			}
+		})
+	}
+}
+
+func TestSetRepositoryIdentifiers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		slug        string
+		wantSlug    string
+		wantWarning bool
+	}{
+		"missing": {slug: "", wantSlug: "owner/renamed"},
+		"same":    {slug: "owner/renamed", wantSlug: "owner/renamed"},
+		// the slug in the configuration is kept not to be replaced by the next plan
+		"renamed": {slug: "owner/repo", wantSlug: "owner/repo", wantWarning: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceKeyPair().Schema, map[string]interface{}{"repository_slug": tc.slug})
+			diags := setRepositoryIdentifiers(d, 1234, "owner/renamed")
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if got := len(diags) == 1 && diags[0].Severity == diag.Warning; got != tc.wantWarning {
+				t.Errorf("unexpected diagnostics: %v", diags)
+			}
+			if got := d.Get("repository_id").(int); got != 1234 {
+				t.Errorf("unexpected repository_id: %d", got)
+			}
+			if got := d.Get("repository_slug").(string); got != tc.wantSlug {
+				t.Errorf("unexpected repository_slug: %q", got)
+			}
+		})
+	}
+}
+
+// diffAttribute returns the new value of the changed attribute, "<computed>" if it is unknown, or "" if it is unchanged.
+func diffAttribute(diff *terraform.InstanceDiff, key string) string {
+	if diff == nil || diff.Attributes[key] == nil {
+		return ""
+	}
+	attr := diff.Attributes[key]
+	switch {
+	case attr.NewComputed:
+		return "<computed>"
+	case attr.Old == attr.New:
+		return ""
+	}
+	return attr.New
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -14,26 +15,29 @@ import (
 
 func resourceCron() *schema.Resource {
 	return &schema.Resource{
-		Description: "The `travis_cron` resource creates a cron job for a branch.",
+		Description: "The `travis_cron` resource creates a cron job for a branch. " +
+			"Since the API cannot update cron jobs, changing `interval` or `dont_run_if_recent_build_exists` replaces the cron job, " +
+			"which changes its ID and resets `next_run`.",
 
 		CreateContext: resourceCronCreate,
 		ReadContext:   resourceCronRead,
+		UpdateContext: resourceCronUpdate,
 		DeleteContext: resourceCronDelete,
 
 		Schema: map[string]*schema.Schema{
 			"repository_id": {
-				Type:         schema.TypeInt,
-				Optional:     true,
-				Description:  "Value uniquely identifying the repository.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_slug"},
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Value uniquely identifying the repository. Defaults to `default_repository_id` of the provider.",
+				ConflictsWith: []string{"repository_slug"},
 			},
 			"repository_slug": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Description:  "Same as {repository.owner.name}/{repository.name}.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_id"},
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Same as {repository.owner.name}/{repository.name}. Defaults to `default_repository_slug` of the provider. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ConflictsWith: []string{"repository_id"},
 			},
 			"branch": {
 				Type:        schema.TypeString,
@@ -44,45 +48,45 @@ func resourceCron() *schema.Resource {
 			"interval": {
 				Type:         schema.TypeString,
 				Required:     true,
-				Description:  "Interval at which this cron runs. Can be daily, weekly, or monthly.",
-				ForceNew:     true,
+				Description:  "Interval at which this cron runs. Can be daily, weekly, or monthly. Changing it replaces the cron job.",
 				ValidateFunc: validation.StringInSlice([]string{"daily", "weekly", "monthly"}, false),
+				ForceNew:     true,
 			},
 			"dont_run_if_recent_build_exists": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Whether a cron build should run if there has been a build on this branch in the last 24 hours.",
+				Description: "Whether a cron build should run if there has been a build on this branch in the last 24 hours. Changing it replaces the cron job.",
 				ForceNew:    true,
 			},
 			"last_run": {
 				Type:        schema.TypeString,
 				Description: "When the cron ran last.",
 				Computed:    true,
-				ForceNew:    true,
 			},
 			"next_run": {
 				Type:        schema.TypeString,
 				Description: "When the cron is scheduled to run next.",
 				Computed:    true,
-				ForceNew:    true,
 			},
 			"created_at": {
 				Type:        schema.TypeString,
 				Description: "When the cron was created.",
 				Computed:    true,
-				ForceNew:    true,
 			},
 			"active": {
 				Type:        schema.TypeBool,
 				Description: "Whether the cron is active.",
 				Computed:    true,
-				ForceNew:    true,
 			},
 		},
 
+		CustomizeDiff: customizeRepository,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: importCron,
 		},
+
+		Timeouts: resourceTimeouts(),
 	}
 }
 
@@ -108,40 +112,40 @@ func resourceCronCreate(ctx context.Context, d *schema.ResourceData, m interface
 	if err := assignCron(cron, d); err != nil {
 		return diag.Errorf("failed to assign cron: %v", err)
 	}
-	return nil
+	client.rememberRepository(cron.Repository)
+	return assignRepositoryIdentifiers(ctx, client, d)
 }
 
 func resourceCronRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	var (
-		client = m.(*Client)
-		cron   *travis.Cron
-		err    error
-	)
-	if repoID := d.Get("repository_id").(int); repoID > 0 {
-		cron, _, err = client.Crons.FindByRepoId(ctx, uint(repoID), d.Get("branch").(string), nil)
-		if err != nil {
-			if isNotFound(err) {
-				d.SetId("")
-				return nil
-			}
-			return diag.Errorf("error reading cron by repo ID (%d) and ID (%s): %s", repoID, d.Id(), err)
-		}
-	} else if repoSlug, ok := d.GetOk("repository_slug"); ok {
-		cron, _, err = client.Crons.FindByRepoSlug(ctx, repoSlug.(string), d.Get("branch").(string), nil)
-		if err != nil {
-			if isNotFound(err) {
-				d.SetId("")
-				return nil
-			}
-			return diag.Errorf("error reading cron by repo slug (%s) and ID (%s): %s", repoSlug, d.Id(), err)
+	client := m.(*Client)
+	cronID, err := strconv.ParseUint(d.Id(), 10, 64)
+	if err != nil {
+		return diag.Errorf("failed to convert cron ID to uint: %s", err)
+	}
+	cron, _, err := client.Crons.Find(ctx, uint(cronID), nil)
+	if err != nil {
+		if isNotFound(err) {
+			// the cron was deleted or replaced by another one outside of Terraform
+			tflog.Warn(ctx, "cron is not found", map[string]interface{}{
+				"id":     d.Id(),
+				"branch": d.Get("branch").(string),
+			})
+			d.SetId("")
+			return nil
 		}
-	} else {
-		return diag.Errorf("one of repository_id or repository_slug must be specified")
+		return diag.Errorf("error reading cron by ID (%s): %s", d.Id(), err)
 	}
 	if err := assignCron(cron, d); err != nil {
 		return diag.Errorf("failed to assign cron: %v", err)
 	}
-	return nil
+	client.rememberRepository(cron.Repository)
+	return assignRepositoryIdentifiers(ctx, client, d)
+}
+
+// resourceCronUpdate only follows a rename of the repository.
+// Travis CI API v3 has no endpoint to update a cron, so the other attributes force a replacement.
+func resourceCronUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return append(repositoryRenamed(d), resourceCronRead(ctx, d, m)...)
 }
 
 func resourceCronDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -196,8 +200,8 @@ func importCron(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*
 	client := m.(*Client)
 
 	args := strings.Split(d.Id(), "/")
-	if len(args) <= 1 {
-		return nil, fmt.Errorf("expected format is \"<repository>/<branch>\", but got invalid: %q", d.Id())
+	if len(args) == 1 {
+		return importCronByID(ctx, d, m)
 	}
 	repo := strings.Join(args[:len(args)-1], "/")
 	branch := args[len(args)-1]
@@ -227,3 +231,28 @@ func importCron(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*
 
 	return []*schema.ResourceData{d}, nil
 }
+
+func importCronByID(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*Client)
+
+	cronID, err := strconv.ParseUint(d.Id(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected format is \"<repository>/<branch>\" or \"<cron id>\", but got invalid: %q", d.Id())
+	}
+	cron, _, err := client.Crons.Find(ctx, uint(cronID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cron by ID (%d): %w", cronID, err)
+	}
+	if cron.Repository == nil || cron.Repository.Id == nil {
+		return nil, fmt.Errorf("repository of cron (%d) is unknown", cronID)
+	}
+	if err := d.Set("repository_id", int(*cron.Repository.Id)); err != nil {
+		return nil, err
+	}
+
+	if err := assignCron(cron, d); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
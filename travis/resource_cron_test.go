@@ -3,6 +3,7 @@ package travis_test
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"testing"
 
@@ -65,6 +66,78 @@ func TestAccResourceCron_basic(t *testing.T) {
 	})
 }
 
+func TestResourceCron_fakeAPI(t *testing.T) {
+	api := newFakeAPI(t)
+	config := func(interval string) string {
+		return api.providerConfig() + fmt.Sprintf(`
+resource "travis_cron" "foo" {
+	repository_id = %d
+	branch        = "main"
+	interval      = %q
+}
+`, fakeRepoID, interval)
+	}
+
+	var createdID string
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("daily"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_cron.foo", "repository_slug", fakeRepoSlug),
+					resource.TestCheckResourceAttr("travis_cron.foo", "interval", "daily"),
+					resource.TestCheckResourceAttrSet("travis_cron.foo", "next_run"),
+					func(s *terraform.State) error {
+						createdID = s.RootModule().Resources["travis_cron.foo"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// the API cannot update a cron, so the interval forces a replacement
+				Config: config("weekly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_cron.foo", "interval", "weekly"),
+					resource.TestCheckResourceAttrSet("travis_cron.foo", "next_run"),
+					func(s *terraform.State) error {
+						id := s.RootModule().Resources["travis_cron.foo"].Primary.ID
+						if id == createdID {
+							return fmt.Errorf("cron is not replaced: %s", id)
+						}
+						if n := api.countRequests(http.MethodDelete, "/cron/"+createdID); n != 1 {
+							return fmt.Errorf("the old cron was deleted %d times", n)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "travis_cron.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "travis_cron.foo",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%d/main", fakeRepoID),
+				ImportStateVerify: true,
+			},
+			{
+				// delete and recreate the cron outside of Terraform
+				PreConfig: func() {
+					api.mu.Lock()
+					defer api.mu.Unlock()
+					api.createCron("main", &travis.CronBody{Interval: "weekly"})
+				},
+				Config:             config("weekly"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func testAccCheckCronResourceDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*tptravis.Client)
 	for _, rs := range s.RootModule().Resources {
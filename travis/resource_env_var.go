@@ -2,10 +2,13 @@ package travis
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/shuheiktgw/go-travis"
@@ -17,22 +20,23 @@ func resourceEnvVar() *schema.Resource {
 
 		CreateContext: resourceEnvVarCreate,
 		ReadContext:   resourceEnvVarRead,
+		UpdateContext: resourceEnvVarUpdate,
 		DeleteContext: resourceEnvVarDelete,
 
 		Schema: map[string]*schema.Schema{
 			"repository_id": {
-				Type:         schema.TypeInt,
-				Optional:     true,
-				Description:  "Value uniquely identifying the repository.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_slug"},
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Value uniquely identifying the repository. Defaults to `default_repository_id` of the provider.",
+				ConflictsWith: []string{"repository_slug"},
 			},
 			"repository_slug": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Description:  "Same as {repository.owner.name}/{repository.name}.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_id"},
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Same as {repository.owner.name}/{repository.name}. Defaults to `default_repository_slug` of the provider. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ConflictsWith: []string{"repository_id"},
 			},
 			"name": {
 				Type:        schema.TypeString,
@@ -44,50 +48,97 @@ func resourceEnvVar() *schema.Resource {
 				Type:         schema.TypeString,
 				Optional:     true,
 				Description:  "The environment variable's value, e.g. bar.",
-				ExactlyOneOf: []string{"value"},
-				ForceNew:     true,
+				ExactlyOneOf: []string{"value", "value_wo"},
 			},
 			"value": {
 				Type:         schema.TypeString,
 				Optional:     true,
 				Description:  "The environment variable's value, e.g. bar.",
 				Sensitive:    true,
-				ExactlyOneOf: []string{"public_value"},
-				ForceNew:     true,
+				ExactlyOneOf: []string{"public_value", "value_wo"},
+			},
+			"value_wo": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The environment variable's value, e.g. bar, which is never stored in the state. Requires Terraform 1.11 or later.",
+				Sensitive:    true,
+				WriteOnly:    true,
+				ExactlyOneOf: []string{"public_value", "value"},
+			},
+			"value_wo_version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Description: "Changing it writes the private value again. " +
+					"Travis CI never returns private values, so their changes outside of Terraform cannot be detected; " +
+					"bump it to restore the value. A changed `value_wo` is written without bumping it.",
+				ConflictsWith: []string{"public_value"},
+			},
+			"value_wo_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The SHA-256 hash of `value_wo` last written, which is compared with the configuration to write a changed `value_wo`.",
 			},
 			"public": {
 				Type:        schema.TypeBool,
 				Description: "Whether this environment variable should be publicly visible or not.",
 				Computed:    true,
-				ForceNew:    true,
 			},
 			"branch": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "The env_var's branch.",
-				ForceNew:    true,
 			},
 		},
 
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			if err := customizeRepository(ctx, d, meta); err != nil {
+				return err
+			}
+
 			publicValue := d.Get("public_value").(string)
 			value := d.Get("value").(string)
+			writeOnly := !d.GetRawConfig().GetAttr("value_wo").IsNull()
 			switch {
-			case publicValue != "" && value == "": // public: true
+			case publicValue != "" && value == "" && !writeOnly: // public: true
 				if err := d.SetNew("public", true); err != nil {
 					return err
 				}
-			case value != "" && publicValue == "": // public: false
+			case (value != "" || writeOnly) && publicValue == "": // public: false
 				if err := d.SetNew("public", false); err != nil {
 					return err
 				}
 			}
+			if err := planEnvVarValueWOHash(d); err != nil {
+				return err
+			}
+
+			// The update API ignores empty fields, so clearing them requires recreating.
+			if d.Id() == "" {
+				return nil
+			}
+			if o, n := d.GetChange("branch"); o.(string) != "" && n.(string) == "" && d.NewValueKnown("branch") {
+				if err := d.ForceNew("branch"); err != nil {
+					return err
+				}
+			}
+			if publicValue == "" && value == "" && !writeOnly && d.NewValueKnown("public_value") && d.NewValueKnown("value") {
+				for _, key := range []string{"public_value", "value"} {
+					if o, _ := d.GetChange(key); o.(string) != "" {
+						if err := d.ForceNew(key); err != nil {
+							return err
+						}
+					}
+				}
+			}
 			return nil
 		},
 
 		Importer: &schema.ResourceImporter{
 			StateContext: importEnvVar,
 		},
+
+		Timeouts: resourceTimeouts(),
 	}
 }
 
@@ -113,7 +164,7 @@ func resourceEnvVarCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	if err := assignEnvVar(envVar, d); err != nil {
 		return diag.Errorf("failed to assign env_var: %v", err)
 	}
-	return nil
+	return assignRepositoryIdentifiers(ctx, client, d)
 }
 
 func resourceEnvVarRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -146,7 +197,36 @@ func resourceEnvVarRead(ctx context.Context, d *schema.ResourceData, m interface
 	if err := assignEnvVar(envVar, d); err != nil {
 		return diag.Errorf("failed to assign env_var: %v", err)
 	}
-	return nil
+	return assignRepositoryIdentifiers(ctx, client, d)
+}
+
+func resourceEnvVarUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var (
+		client = m.(*Client)
+		envVar *travis.EnvVar
+		err    error
+		diags  = repositoryRenamed(d)
+	)
+	if !d.HasChangesExcept("repository_id", "repository_slug") {
+		return diags
+	}
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		envVar, _, err = client.EnvVars.UpdateByRepoId(ctx, uint(repoID), d.Id(), generateEnvVarBody(d))
+		if err != nil {
+			return diag.Errorf("error updating env var by repo ID (%d) and ID (%s): %s", repoID, d.Id(), err)
+		}
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		envVar, _, err = client.EnvVars.UpdateByRepoSlug(ctx, repoSlug, d.Id(), generateEnvVarBody(d))
+		if err != nil {
+			return diag.Errorf("error updating env var by repo slug (%s) and ID (%s): %s", repoSlug, d.Id(), err)
+		}
+	} else {
+		return diag.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	if err := assignEnvVar(envVar, d); err != nil {
+		return diag.Errorf("failed to assign env_var: %v", err)
+	}
+	return diags
 }
 
 func resourceEnvVarDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -174,6 +254,8 @@ func generateEnvVarBody(d *schema.ResourceData) *travis.EnvVarBody {
 	value := d.Get("value").(string)
 	if public {
 		value = d.Get("public_value").(string)
+	} else if value == "" {
+		value = envVarValueWO(d)
 	}
 
 	if value == "" {
@@ -188,6 +270,37 @@ func generateEnvVarBody(d *schema.ResourceData) *travis.EnvVarBody {
 	}
 }
 
+// planEnvVarValueWOHash plans value_wo_hash by the configuration,
+// so that a changed value_wo is written even if value_wo_version is not changed.
+func planEnvVarValueWOHash(d *schema.ResourceDiff) error {
+	v := d.GetRawConfig().GetAttr("value_wo")
+	hash := ""
+	switch {
+	case !v.IsKnown():
+		return d.SetNewComputed("value_wo_hash")
+	case !v.IsNull():
+		hash = hashEnvVarValue(v.AsString())
+	}
+	if hash == d.Get("value_wo_hash").(string) {
+		return nil
+	}
+	return d.SetNew("value_wo_hash", hash)
+}
+
+func hashEnvVarValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// envVarValueWO returns value_wo, which is only available in the configuration.
+func envVarValueWO(d *schema.ResourceData) string {
+	v, diags := d.GetRawConfigAt(cty.GetAttrPath("value_wo"))
+	if diags.HasError() || !v.IsKnown() || v.IsNull() || !v.Type().Equals(cty.String) {
+		return ""
+	}
+	return v.AsString()
+}
+
 func assignEnvVar(envVar *travis.EnvVar, d *schema.ResourceData) error {
 	d.SetId(*envVar.Id)
 	if err := d.Set("name", envVar.Name); err != nil {
@@ -2,6 +2,8 @@ package travis_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"testing"
 
@@ -14,7 +16,10 @@ import (
 )
 
 func TestAccResourceEnvVar_basic(t *testing.T) {
-	var envVar travis.EnvVar
+	var (
+		envVar   travis.EnvVar
+		envVarID string
+	)
 	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
 
 	resource.Test(t, resource.TestCase{
@@ -26,6 +31,7 @@ func TestAccResourceEnvVar_basic(t *testing.T) {
 				Config: testAccEnvVarResource(rName),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckEnvVarResourceExists("travis_env_var.foo", &envVar),
+					testAccCheckEnvVarResourceNotRecreated("travis_env_var.foo", &envVarID),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "repository_slug", testRepoSlug),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "name", rName),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "value", "secret"),
@@ -44,6 +50,7 @@ func TestAccResourceEnvVar_basic(t *testing.T) {
 				Config: testAccPublicEnvVarResource(rName),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckEnvVarResourceExists("travis_env_var.foo", &envVar),
+					testAccCheckEnvVarResourceNotRecreated("travis_env_var.foo", &envVarID),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "repository_slug", testRepoSlug),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "name", rName),
 					resource.TestCheckResourceAttr("travis_env_var.foo", "public_value", "public"),
@@ -73,6 +80,78 @@ func TestAccResourceEnvVar_basic(t *testing.T) {
 	})
 }
 
+func TestResourceEnvVar_valueWriteOnly(t *testing.T) {
+	// write-only attributes are supported since Terraform 1.11
+	testSkipBelowTerraform(t, "1.11.0")
+
+	api := newFakeAPI(t)
+	config := func(value string, version int) string {
+		return api.providerConfig() + fmt.Sprintf(`
+resource "travis_env_var" "foo" {
+	repository_slug  = %q
+	name             = "SECRET"
+	value_wo         = %q
+	value_wo_version = %d
+}
+`, fakeRepoSlug, value, version)
+	}
+	hash := func(value string) string {
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	}
+	checkValue := func(want string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			api.mu.Lock()
+			defer api.mu.Unlock()
+			for _, envVar := range api.envVars {
+				if *envVar.Name == "SECRET" && *envVar.Value == want && !*envVar.Public {
+					return nil
+				}
+			}
+			return fmt.Errorf("env var is not written: %v", api.envVars)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("secret", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_env_var.foo", "public", "false"),
+					resource.TestCheckNoResourceAttr("travis_env_var.foo", "value_wo"),
+					resource.TestCheckResourceAttr("travis_env_var.foo", "value_wo_hash", hash("secret")),
+					checkValue("secret"),
+				),
+			},
+			{
+				// modified outside of Terraform, which cannot be detected
+				PreConfig: func() {
+					api.mu.Lock()
+					defer api.mu.Unlock()
+					for _, envVar := range api.envVars {
+						envVar.Value = travis.String("modified")
+					}
+				},
+				Config:   config("secret", 1),
+				PlanOnly: true,
+			},
+			{
+				Config: config("secret", 2),
+				Check:  checkValue("secret"),
+			},
+			{
+				// the changed value is detected by the hash without bumping the version
+				Config: config("changed", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_env_var.foo", "value_wo_hash", hash("changed")),
+					checkValue("changed"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckEnvVarResourceDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*tptravis.Client)
 	for _, rs := range s.RootModule().Resources {
@@ -111,6 +190,23 @@ func testAccCheckEnvVarResourceExists(resourceName string, envVar *travis.EnvVar
 	}
 }
 
+func testAccCheckEnvVarResourceNotRecreated(resourceName string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resourceName)
+		}
+		if *id == "" {
+			*id = rs.Primary.ID
+			return nil
+		}
+		if rs.Primary.ID != *id {
+			return fmt.Errorf("env var was recreated: ID changed from %q to %q", *id, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
 func testAccEnvVarResource(name string) string {
 	return fmt.Sprintf(`
 resource "travis_env_var" "foo" {
@@ -0,0 +1,461 @@
+package travis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func resourceEnvVars() *schema.Resource {
+	return &schema.Resource{
+		Description: "The `travis_env_vars` resource manages a set of environment variables of a repository at once. " +
+			"Variables are identified by the pair of the name and the branch.",
+
+		CreateContext: resourceEnvVarsCreate,
+		ReadContext:   resourceEnvVarsRead,
+		UpdateContext: resourceEnvVarsUpdate,
+		DeleteContext: resourceEnvVarsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Value uniquely identifying the repository. Defaults to `default_repository_id` of the provider.",
+				ConflictsWith: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Same as {repository.owner.name}/{repository.name}. Defaults to `default_repository_slug` of the provider. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ConflictsWith: []string{"repository_id"},
+			},
+			"variable": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The environment variables of the repository.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The environment variable name, e.g. FOO.",
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"value": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Sensitive:    true,
+							Description:  "The environment variable's value, e.g. bar. The value of a private variable cannot be read back from the API.",
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"public": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether this environment variable should be publicly visible or not.",
+						},
+						"branch": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The env_var's branch. All branches if empty.",
+						},
+					},
+				},
+			},
+			"values_version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Description: "Changing it writes the values of all private variables again. " +
+					"Travis CI never returns private values, so their changes outside of Terraform cannot be detected; " +
+					"bump it to restore them.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to delete the environment variables of the repository which are not declared in `variable`.",
+			},
+		},
+
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			if err := customizeRepository(ctx, d, meta); err != nil {
+				return err
+			}
+			seen := map[envVarKey]bool{}
+			for _, v := range d.Get("variable").(*schema.Set).List() {
+				key := envVarKeyOf(v.(map[string]interface{}))
+				if key.name == "" {
+					// unknown until apply
+					continue
+				}
+				if seen[key] {
+					return fmt.Errorf("variable %s is declared more than once", key)
+				}
+				seen[key] = true
+			}
+			return nil
+		},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importEnvVars,
+		},
+
+		Timeouts: resourceTimeouts(),
+	}
+}
+
+// envVarKey identifies an environment variable of a repository.
+type envVarKey struct {
+	name   string
+	branch string
+}
+
+func (k envVarKey) String() string {
+	if k.branch == "" {
+		return strconv.Quote(k.name)
+	}
+	return fmt.Sprintf("%q on branch %q", k.name, k.branch)
+}
+
+func envVarKeyOf(v map[string]interface{}) envVarKey {
+	return envVarKey{name: v["name"].(string), branch: v["branch"].(string)}
+}
+
+func envVarKeyOfAPI(envVar *travis.EnvVar) envVarKey {
+	var key envVarKey
+	if envVar.Name != nil {
+		key.name = *envVar.Name
+	}
+	if envVar.Branch != nil {
+		key.branch = *envVar.Branch
+	}
+	return key
+}
+
+// repositoryEnvVars calls the env var endpoints of a repository by the ID if known, otherwise by the slug.
+type repositoryEnvVars struct {
+	client *Client
+	id     int
+	slug   string
+}
+
+func newRepositoryEnvVars(client *Client, d *schema.ResourceData) (*repositoryEnvVars, error) {
+	r := &repositoryEnvVars{
+		client: client,
+		id:     d.Get("repository_id").(int),
+		slug:   d.Get("repository_slug").(string),
+	}
+	if r.id <= 0 && r.slug == "" {
+		return nil, fmt.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	return r, nil
+}
+
+func (r *repositoryEnvVars) String() string {
+	if r.id > 0 {
+		return fmt.Sprintf("repo ID (%d)", r.id)
+	}
+	return fmt.Sprintf("repo slug (%s)", r.slug)
+}
+
+func (r *repositoryEnvVars) list(ctx context.Context) ([]*travis.EnvVar, error) {
+	var (
+		envVars []*travis.EnvVar
+		err     error
+	)
+	if r.id > 0 {
+		envVars, _, err = r.client.EnvVars.ListByRepoId(ctx, uint(r.id))
+	} else {
+		envVars, _, err = r.client.EnvVars.ListByRepoSlug(ctx, r.slug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing env vars by %s: %w", r, err)
+	}
+	return envVars, nil
+}
+
+func (r *repositoryEnvVars) create(ctx context.Context, body *travis.EnvVarBody) error {
+	var err error
+	if r.id > 0 {
+		_, _, err = r.client.EnvVars.CreateByRepoId(ctx, uint(r.id), body)
+	} else {
+		_, _, err = r.client.EnvVars.CreateByRepoSlug(ctx, r.slug, body)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating env var %q by %s: %w", body.Name, r, err)
+	}
+	return nil
+}
+
+func (r *repositoryEnvVars) update(ctx context.Context, id string, body *travis.EnvVarBody) error {
+	var err error
+	if r.id > 0 {
+		_, _, err = r.client.EnvVars.UpdateByRepoId(ctx, uint(r.id), id, body)
+	} else {
+		_, _, err = r.client.EnvVars.UpdateByRepoSlug(ctx, r.slug, id, body)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating env var %q by %s and ID (%s): %w", body.Name, r, id, err)
+	}
+	return nil
+}
+
+func (r *repositoryEnvVars) delete(ctx context.Context, id string) error {
+	var err error
+	if r.id > 0 {
+		_, err = r.client.EnvVars.DeleteByRepoId(ctx, uint(r.id), id)
+	} else {
+		_, err = r.client.EnvVars.DeleteByRepoSlug(ctx, r.slug, id)
+	}
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("error deleting env var by %s and ID (%s): %w", r, id, err)
+	}
+	return nil
+}
+
+func resourceEnvVarsCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	if err := reconcileEnvVars(ctx, client, d); err != nil {
+		return diag.FromErr(err)
+	}
+	return append(diags, resourceEnvVarsRead(ctx, d, m)...)
+}
+
+func resourceEnvVarsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	repo, err := newRepositoryEnvVars(client, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	envVars, err := repo.list(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	// Private values are not returned by the API, so the ones in the state are kept.
+	managed := map[envVarKey]map[string]interface{}{}
+	for _, v := range d.Get("variable").(*schema.Set).List() {
+		v := v.(map[string]interface{})
+		managed[envVarKeyOf(v)] = v
+	}
+	exclusive := d.Get("exclusive").(bool)
+	variables := make([]interface{}, 0, len(envVars))
+	for _, envVar := range envVars {
+		key := envVarKeyOfAPI(envVar)
+		prev, ok := managed[key]
+		if !ok && !exclusive {
+			continue
+		}
+		variables = append(variables, flattenEnvVar(envVar, prev))
+	}
+	if err := d.Set("variable", variables); err != nil {
+		return diag.Errorf("failed to assign env_vars: %v", err)
+	}
+	setRepositoryResourceID(d)
+	return diags
+}
+
+func resourceEnvVarsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	diags := repositoryRenamed(d)
+	if d.HasChanges("variable", "values_version", "exclusive") {
+		if err := reconcileEnvVars(ctx, client, d); err != nil {
+			// keep the previous state so that the remaining changes are planned again
+			d.Partial(true)
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+	return append(diags, resourceEnvVarsRead(ctx, d, m)...)
+}
+
+func resourceEnvVarsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	repo, err := newRepositoryEnvVars(client, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	envVars, err := repo.list(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	managed := map[envVarKey]bool{}
+	for _, v := range d.Get("variable").(*schema.Set).List() {
+		managed[envVarKeyOf(v.(map[string]interface{}))] = true
+	}
+	for _, envVar := range envVars {
+		if !managed[envVarKeyOfAPI(envVar)] {
+			continue
+		}
+		if err := repo.delete(ctx, *envVar.Id); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("")
+	return nil
+}
+
+// reconcileEnvVars makes the environment variables of the repository match the declared ones.
+// Only the variables changed from the previous state are written unless values_version is changed,
+// and the existing variables are listed once.
+func reconcileEnvVars(ctx context.Context, client *Client, d *schema.ResourceData) error {
+	repo, err := newRepositoryEnvVars(client, d)
+	if err != nil {
+		return err
+	}
+	setRepositoryResourceID(d)
+	envVars, err := repo.list(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[envVarKey]*travis.EnvVar, len(envVars))
+	for _, envVar := range envVars {
+		existing[envVarKeyOfAPI(envVar)] = envVar
+	}
+
+	o, n := d.GetChange("variable")
+	previous := map[envVarKey]map[string]interface{}{}
+	for _, v := range o.(*schema.Set).List() {
+		v := v.(map[string]interface{})
+		previous[envVarKeyOf(v)] = v
+	}
+	rewrite := d.HasChange("values_version")
+	declared := map[envVarKey]bool{}
+	for _, v := range n.(*schema.Set).List() {
+		v := v.(map[string]interface{})
+		key := envVarKeyOf(v)
+		declared[key] = true
+		body := &travis.EnvVarBody{
+			Name:   key.name,
+			Value:  v["value"].(string),
+			Public: v["public"].(bool),
+			Branch: key.branch,
+		}
+		envVar, ok := existing[key]
+		if !ok {
+			if err := repo.create(ctx, body); err != nil {
+				return err
+			}
+			continue
+		}
+		if prev, ok := previous[key]; ok && envVarUnchanged(prev, v, envVar) && (!rewrite || body.Public) {
+			continue
+		}
+		if err := repo.update(ctx, *envVar.Id, body); err != nil {
+			return err
+		}
+	}
+
+	exclusive := d.Get("exclusive").(bool)
+	for key, envVar := range existing {
+		if declared[key] {
+			continue
+		}
+		if _, ok := previous[key]; !ok && !exclusive {
+			// not managed by this resource
+			continue
+		}
+		tflog.Debug(ctx, "delete env var", map[string]interface{}{
+			"name":   key.name,
+			"branch": key.branch,
+		})
+		if err := repo.delete(ctx, *envVar.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envVarUnchanged reports whether the declared variable is the same as the previous one and the existing one.
+func envVarUnchanged(prev, v map[string]interface{}, envVar *travis.EnvVar) bool {
+	if prev["value"] != v["value"] || prev["public"] != v["public"] {
+		return false
+	}
+	if envVar.Public == nil || *envVar.Public != v["public"].(bool) {
+		return false
+	}
+	return !*envVar.Public || (envVar.Value != nil && *envVar.Value == v["value"].(string))
+}
+
+func flattenEnvVar(envVar *travis.EnvVar, prev map[string]interface{}) map[string]interface{} {
+	key := envVarKeyOfAPI(envVar)
+	v := map[string]interface{}{
+		"name":   key.name,
+		"branch": key.branch,
+		"public": envVar.Public != nil && *envVar.Public,
+		"value":  "",
+	}
+	switch {
+	case v["public"].(bool) && envVar.Value != nil:
+		v["value"] = *envVar.Value
+	case prev != nil && prev["public"] == false:
+		v["value"] = prev["value"]
+	}
+	return v
+}
+
+// setRepositoryResourceID uses the repository ID as the resource ID, or the slug until the ID is known.
+func setRepositoryResourceID(d *schema.ResourceData) {
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		d.SetId(strconv.Itoa(repoID))
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		d.SetId(repoSlug)
+	}
+}
+
+// importEnvVars imports all the environment variables of the repository.
+// The values of private variables cannot be read back and are reconciled on the next apply.
+func importEnvVars(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if repoID, err := strconv.Atoi(d.Id()); err == nil {
+		if err := d.Set("repository_id", repoID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := d.Set("repository_slug", d.Id()); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.Set("exclusive", false); err != nil {
+		return nil, err
+	}
+
+	repo, err := newRepositoryEnvVars(m.(*Client), d)
+	if err != nil {
+		return nil, err
+	}
+	envVars, err := repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	variables := make([]interface{}, 0, len(envVars))
+	for _, envVar := range envVars {
+		variables = append(variables, flattenEnvVar(envVar, nil))
+	}
+	if err := d.Set("variable", variables); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,207 @@
+package travis_test
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func TestResourceEnvVars_fakeAPI(t *testing.T) {
+	api := newFakeAPI(t)
+	config := func(exclusive bool, variables string) string {
+		return api.providerConfig() + fmt.Sprintf(`
+resource "travis_env_vars" "foo" {
+	repository_slug = %q
+	exclusive       = %t
+	%s
+}
+`, fakeRepoSlug, exclusive, variables)
+	}
+	checkEnvVars := func(want map[string]string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			api.mu.Lock()
+			defer api.mu.Unlock()
+			got := map[string]string{}
+			for _, envVar := range api.envVars {
+				key := *envVar.Name
+				if envVar.Branch != nil {
+					key += "@" + *envVar.Branch
+				}
+				got[key] = *envVar.Value
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				return fmt.Errorf("unexpected env vars: %v", got)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					api.mu.Lock()
+					defer api.mu.Unlock()
+					api.createEnvVar(&travis.EnvVarBody{Name: "UNMANAGED", Value: "unmanaged", Public: true})
+				},
+				Config: config(false, `
+	variable {
+		name   = "PUBLIC"
+		value  = "public"
+		public = true
+	}
+	variable {
+		name  = "SECRET"
+		value = "secret"
+	}
+	variable {
+		name   = "SECRET"
+		value  = "secret on main"
+		branch = "main"
+	}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_env_vars.foo", "id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_env_vars.foo", "repository_id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_env_vars.foo", "variable.#", "3"),
+					checkEnvVars(map[string]string{
+						"UNMANAGED":   "unmanaged",
+						"PUBLIC":      "public",
+						"SECRET":      "secret",
+						"SECRET@main": "secret on main",
+					}),
+				),
+			},
+			{
+				Config: config(false, `
+	variable {
+		name   = "PUBLIC"
+		value  = "updated"
+		public = true
+	}
+	variable {
+		name   = "SECRET"
+		value  = "secret on main"
+		branch = "main"
+	}
+	variable {
+		name  = "ADDED"
+		value = "added"
+	}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_env_vars.foo", "variable.#", "3"),
+					checkEnvVars(map[string]string{
+						"UNMANAGED":   "unmanaged",
+						"PUBLIC":      "updated",
+						"SECRET@main": "secret on main",
+						"ADDED":       "added",
+					}),
+					func(*terraform.State) error {
+						if n := api.countRequests(http.MethodPatch, "/repo/"); n != 1 {
+							return fmt.Errorf("unchanged env vars are updated: %d requests", n)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: config(true, `
+	variable {
+		name   = "PUBLIC"
+		value  = "updated"
+		public = true
+	}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_env_vars.foo", "variable.#", "1"),
+					checkEnvVars(map[string]string{
+						"PUBLIC": "updated",
+					}),
+				),
+			},
+			{
+				ResourceName: "travis_env_vars.foo",
+				ImportState:  true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("unexpected states: %d", len(states))
+					}
+					if n := states[0].Attributes["variable.#"]; n != "1" {
+						return fmt.Errorf("unexpected variables: %s", n)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestResourceEnvVars_valuesVersion(t *testing.T) {
+	api := newFakeAPI(t)
+	config := func(version int) string {
+		return api.providerConfig() + fmt.Sprintf(`
+resource "travis_env_vars" "foo" {
+	repository_slug = %q
+	values_version  = %d
+
+	variable {
+		name  = "SECRET"
+		value = "secret"
+	}
+	variable {
+		name   = "PUBLIC"
+		value  = "public"
+		public = true
+	}
+}
+`, fakeRepoSlug, version)
+	}
+	modify := func() {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		for _, envVar := range api.envVars {
+			if !*envVar.Public {
+				envVar.Value = travis.String("modified")
+			}
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config(1),
+			},
+			{
+				PreConfig: modify,
+				Config:    config(2),
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						api.mu.Lock()
+						defer api.mu.Unlock()
+						for _, envVar := range api.envVars {
+							if *envVar.Name == "SECRET" && *envVar.Value != "secret" {
+								return fmt.Errorf("private value is not written again: %s", *envVar.Value)
+							}
+						}
+						return nil
+					},
+					func(*terraform.State) error {
+						// only the private variable is written again
+						if n := api.countRequests(http.MethodPatch, "/repo/"); n != 1 {
+							return fmt.Errorf("unexpected updates: %d", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,180 @@
+package travis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func resourceGeneratedKeyPair() *schema.Resource {
+	return &schema.Resource{
+		Description: "The `travis_generated_key_pair` resource lets Travis CI generate an RSA key pair for a repo. " +
+			"The private key never leaves Travis CI. " +
+			"The generated key pair cannot be deleted, so destroying this resource only removes it from the state.",
+
+		CreateContext: resourceGeneratedKeyPairCreate,
+		ReadContext:   resourceGeneratedKeyPairRead,
+		UpdateContext: resourceGeneratedKeyPairUpdate,
+		DeleteContext: resourceGeneratedKeyPairDelete,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Value uniquely identifying the repository.",
+				ExactlyOneOf: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Same as {repository.owner.name}/{repository.name}. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ExactlyOneOf: []string{"repository_id"},
+			},
+			"fingerprint": {
+				Type:        schema.TypeString,
+				Description: "Fingerprint of the RSA key",
+				Computed:    true,
+			},
+			"public_key": {
+				Type:        schema.TypeString,
+				Description: "The public key.",
+				Computed:    true,
+			},
+		},
+
+		CustomizeDiff: customizeConfiguredRepository,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importGeneratedKeyPair,
+		},
+	}
+}
+
+func resourceGeneratedKeyPairCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var (
+		client  = m.(*Client)
+		keyPair *travis.KeyPair
+		err     error
+	)
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		keyPair, _, err = client.GeneratedKeyPair.CreateByRepoId(ctx, uint(repoID))
+		if err != nil {
+			return diag.Errorf("error generating key pair by repo ID (%d): %s", repoID, err)
+		}
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		keyPair, _, err = client.GeneratedKeyPair.CreateByRepoSlug(ctx, repoSlug)
+		if err != nil {
+			return diag.Errorf("error generating key pair by repo slug (%s): %s", repoSlug, err)
+		}
+	} else {
+		return diag.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	if err := assignGeneratedKeyPair(keyPair, d); err != nil {
+		return diag.Errorf("failed to assign generated key_pair: %v", err)
+	}
+	return diags
+}
+
+func resourceGeneratedKeyPairRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var (
+		client  = m.(*Client)
+		keyPair *travis.KeyPair
+		err     error
+	)
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		keyPair, _, err = client.GeneratedKeyPair.FindByRepoId(ctx, uint(repoID))
+		if err != nil {
+			if isNotFound(err) {
+				d.SetId("")
+				return nil
+			}
+			return diag.Errorf("error reading generated key pair by repo ID (%d): %s", repoID, err)
+		}
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		keyPair, _, err = client.GeneratedKeyPair.FindByRepoSlug(ctx, repoSlug)
+		if err != nil {
+			if isNotFound(err) {
+				d.SetId("")
+				return nil
+			}
+			return diag.Errorf("error reading generated key pair by repo slug (%s): %s", repoSlug, err)
+		}
+	} else {
+		return diag.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	if err := assignGeneratedKeyPair(keyPair, d); err != nil {
+		return diag.Errorf("failed to assign generated key_pair: %v", err)
+	}
+	return diags
+}
+
+// resourceGeneratedKeyPairUpdate only follows a rename of the repository since the key pair cannot be updated.
+func resourceGeneratedKeyPairUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return append(repositoryRenamed(d), resourceGeneratedKeyPairRead(ctx, d, m)...)
+}
+
+func resourceGeneratedKeyPairDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+func assignGeneratedKeyPair(keyPair *travis.KeyPair, d *schema.ResourceData) error {
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		d.SetId(strconv.Itoa(repoID))
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		d.SetId(repoSlug)
+	}
+	if err := d.Set("public_key", keyPair.PublicKey); err != nil {
+		return err
+	}
+	if err := d.Set("fingerprint", keyPair.Fingerprint); err != nil {
+		return err
+	}
+	return nil
+}
+
+func importGeneratedKeyPair(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	var (
+		client  = m.(*Client)
+		repo    = d.Id()
+		keyPair *travis.KeyPair
+	)
+
+	if repoID, err := strconv.Atoi(repo); err == nil {
+		keyPair, _, err = client.GeneratedKeyPair.FindByRepoId(ctx, uint(repoID))
+		if err != nil {
+			return nil, fmt.Errorf("error getting generated key pair of repo id (%d): %w", repoID, err)
+		}
+		if err := d.Set("repository_id", repoID); err != nil {
+			return nil, err
+		}
+	} else {
+		keyPair, _, err = client.GeneratedKeyPair.FindByRepoSlug(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("error getting generated key pair of repo slug (%q): %w", repo, err)
+		}
+		if err := d.Set("repository_slug", repo); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := assignGeneratedKeyPair(keyPair, d); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,47 @@
+package travis_test
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestResourceGeneratedKeyPair_fakeAPI(t *testing.T) {
+	for _, tc := range []struct {
+		attr  string
+		value string
+	}{
+		{attr: "repository_id", value: strconv.Itoa(fakeRepoID)},
+		{attr: "repository_slug", value: fakeRepoSlug},
+	} {
+		t.Run(tc.attr, func(t *testing.T) {
+			api := newFakeAPI(t)
+
+			resource.Test(t, resource.TestCase{
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: api.providerConfig() + fmt.Sprintf(`
+resource "travis_generated_key_pair" "foo" {
+	%s = %q
+}
+`, tc.attr, tc.value),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("travis_generated_key_pair.foo", "id", strconv.Itoa(fakeRepoID)),
+							resource.TestCheckResourceAttr("travis_generated_key_pair.foo", "fingerprint", fmt.Sprintf("%x", md5.Sum([]byte("generated private key 1")))),
+							resource.TestCheckResourceAttrSet("travis_generated_key_pair.foo", "public_key"),
+						),
+					},
+					{
+						ResourceName:      "travis_generated_key_pair.foo",
+						ImportState:       true,
+						ImportStateVerify: true,
+					},
+				},
+			})
+		})
+	}
+}
@@ -2,6 +2,8 @@ package travis
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,18 +20,18 @@ func resourceKeyPair() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"repository_id": &schema.Schema{
-				Type:         schema.TypeInt,
-				Optional:     true,
-				Description:  "Value uniquely identifying the repository.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_slug"},
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Value uniquely identifying the repository. Defaults to `default_repository_id` of the provider.",
+				ConflictsWith: []string{"repository_slug"},
 			},
 			"repository_slug": &schema.Schema{
-				Type:         schema.TypeString,
-				Optional:     true,
-				Description:  "Same as {repository.owner.name}/{repository.name}.",
-				ForceNew:     true,
-				ExactlyOneOf: []string{"repository_id"},
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "Same as {repository.owner.name}/{repository.name}. Defaults to `default_repository_slug` of the provider. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ConflictsWith: []string{"repository_id"},
 			},
 			"description": &schema.Schema{
 				Type:        schema.TypeString,
@@ -53,6 +55,14 @@ func resourceKeyPair() *schema.Resource {
 				Computed:    true,
 			},
 		},
+
+		CustomizeDiff: customizeRepository,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importKeyPair,
+		},
+
+		Timeouts: resourceTimeouts(),
 	}
 }
 
@@ -62,8 +72,8 @@ func resourceKeyPairCreate(ctx context.Context, d *schema.ResourceData, m interf
 		keyPair *travis.KeyPair
 		err     error
 	)
-	if repoID, ok := d.GetOk("repository_id"); ok {
-		keyPair, _, err = client.KeyPair.CreateByRepoId(ctx, repoID.(uint), generateKeyPairBody(d))
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		keyPair, _, err = client.KeyPair.CreateByRepoId(ctx, uint(repoID), generateKeyPairBody(d))
 		if err != nil {
 			return diag.Errorf("error creating key pair by repo ID (%d): %s", repoID, err)
 		}
@@ -75,10 +85,14 @@ func resourceKeyPairCreate(ctx context.Context, d *schema.ResourceData, m interf
 	} else {
 		return diag.Errorf("one of repository_id or repository_slug must be specified")
 	}
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
 	if err := assignKeyPair(keyPair, d); err != nil {
 		return diag.Errorf("failed to assign key_pair: %v", err)
 	}
-	return nil
+	return diags
 }
 
 func resourceKeyPairRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -87,8 +101,8 @@ func resourceKeyPairRead(ctx context.Context, d *schema.ResourceData, m interfac
 		keyPair *travis.KeyPair
 		err     error
 	)
-	if repoID, ok := d.GetOk("repository_id"); ok {
-		keyPair, _, err = client.KeyPair.FindByRepoId(ctx, repoID.(uint))
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		keyPair, _, err = client.KeyPair.FindByRepoId(ctx, uint(repoID))
 		if err != nil {
 			if isNotFound(err) {
 				d.SetId("")
@@ -108,10 +122,14 @@ func resourceKeyPairRead(ctx context.Context, d *schema.ResourceData, m interfac
 	} else {
 		return diag.Errorf("one of repository_id or repository_slug must be specified")
 	}
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
 	if err := assignKeyPair(keyPair, d); err != nil {
 		return diag.Errorf("failed to assign key_pair: %v", err)
 	}
-	return nil
+	return diags
 }
 
 func resourceKeyPairUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -130,26 +148,28 @@ func resourceKeyPairUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	if update.Value != "" || update.Description != "" {
-		if repoID, ok := d.GetOk("repository_id"); ok {
-			_, _, err = client.KeyPair.UpdateByRepoId(ctx, repoID.(uint), update)
+		if repoID := d.Get("repository_id").(int); repoID > 0 {
+			_, _, err = client.KeyPair.UpdateByRepoId(ctx, uint(repoID), update)
 			if err != nil {
-				return diag.Errorf("error updating key pair by repo ID (%s).", repoID)
+				return diag.Errorf("error updating key pair by repo ID (%d): %s", repoID, err)
 			}
 		} else if repoSlug, ok := d.GetOk("repository_slug"); ok {
 			_, _, err = client.KeyPair.UpdateByRepoSlug(ctx, repoSlug.(string), update)
 			if err != nil {
-				return diag.Errorf("error updating key pair by repo slug (%s).", repoSlug)
+				return diag.Errorf("error updating key pair by repo slug (%s): %s", repoSlug, err)
 			}
+		} else {
+			return diag.Errorf("one of repository_id or repository_slug must be specified")
 		}
 	}
 
-	return resourceKeyPairRead(ctx, d, m)
+	return append(repositoryRenamed(d), resourceKeyPairRead(ctx, d, m)...)
 }
 
 func resourceKeyPairDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*Client)
-	if repoID, ok := d.GetOk("repository_id"); ok {
-		_, err := client.KeyPair.DeleteByRepoId(ctx, repoID.(uint))
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		_, err := client.KeyPair.DeleteByRepoId(ctx, uint(repoID))
 		if err != nil {
 			return diag.Errorf("error deleting key pair by repo ID (%d): %s", repoID, err)
 		}
@@ -173,11 +193,14 @@ func generateKeyPairBody(d *schema.ResourceData) *travis.KeyPairBody {
 }
 
 func assignKeyPair(keyPair *travis.KeyPair, d *schema.ResourceData) error {
-	if val, ok := d.GetOk("repository_id"); ok {
-		d.SetId(val.(string))
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		d.SetId(strconv.Itoa(repoID))
 	} else if val, ok := d.GetOk("repository_slug"); ok {
 		d.SetId(val.(string))
 	}
+	if err := d.Set("description", keyPair.Description); err != nil {
+		return err
+	}
 	if err := d.Set("public_key", keyPair.PublicKey); err != nil {
 		return err
 	}
@@ -186,3 +209,36 @@ func assignKeyPair(keyPair *travis.KeyPair, d *schema.ResourceData) error {
 	}
 	return nil
 }
+
+func importKeyPair(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	var (
+		client  = m.(*Client)
+		repo    = d.Id()
+		keyPair *travis.KeyPair
+	)
+
+	if repoID, err := strconv.Atoi(repo); err == nil {
+		keyPair, _, err = client.KeyPair.FindByRepoId(ctx, uint(repoID))
+		if err != nil {
+			return nil, fmt.Errorf("error getting key pair of repo id (%d): %w", repoID, err)
+		}
+		if err := d.Set("repository_id", repoID); err != nil {
+			return nil, err
+		}
+	} else {
+		keyPair, _, err = client.KeyPair.FindByRepoSlug(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("error getting key pair of repo slug (%q): %w", repo, err)
+		}
+		if err := d.Set("repository_slug", repo); err != nil {
+			return nil, err
+		}
+	}
+
+	// value (the private key) cannot be read back and is reconciled on the next apply.
+	if err := assignKeyPair(keyPair, d); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -2,11 +2,15 @@ package travis_test
 
 import (
 	"context"
+	"crypto/md5"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
@@ -38,6 +42,169 @@ func TestAccResourceKeyPair_basic(t *testing.T) {
 					resource.TestCheckResourceAttr("travis_key_pair.foo", "public_key", testAccPublicKey),
 				),
 			},
+			{
+				ResourceName:            "travis_key_pair.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"value"},
+			},
+		},
+	})
+}
+
+func TestResourceKeyPair_fakeAPI(t *testing.T) {
+	for _, tc := range []struct {
+		attr  string
+		value string
+	}{
+		{attr: "repository_id", value: strconv.Itoa(fakeRepoID)},
+		{attr: "repository_slug", value: fakeRepoSlug},
+	} {
+		t.Run(tc.attr, func(t *testing.T) {
+			api := newFakeAPI(t)
+			config := func(desc, value string) string {
+				return api.providerConfig() + fmt.Sprintf(`
+resource "travis_key_pair" "foo" {
+	%s = %q
+	description = %q
+	value       = %q
+}
+`, tc.attr, tc.value, desc, value)
+			}
+
+			resource.Test(t, resource.TestCase{
+				Providers: testAccProviders,
+				CheckDestroy: func(*terraform.State) error {
+					api.mu.Lock()
+					defer api.mu.Unlock()
+					if api.keyPair != nil {
+						return fmt.Errorf("key pair still exists")
+					}
+					return nil
+				},
+				Steps: []resource.TestStep{
+					{
+						Config: config("first", "first private key"),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "id", strconv.Itoa(fakeRepoID)),
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_id", strconv.Itoa(fakeRepoID)),
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_slug", fakeRepoSlug),
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "description", "first"),
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "fingerprint", fmt.Sprintf("%x", md5.Sum([]byte("first private key")))),
+						),
+					},
+					{
+						Config: config("second", "second private key"),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "description", "second"),
+							resource.TestCheckResourceAttr("travis_key_pair.foo", "fingerprint", fmt.Sprintf("%x", md5.Sum([]byte("second private key")))),
+							func(*terraform.State) error {
+								api.mu.Lock()
+								defer api.mu.Unlock()
+								if api.keyPair == nil || *api.keyPair.Description != "second" {
+									return fmt.Errorf("key pair is not updated: %+v", api.keyPair)
+								}
+								return nil
+							},
+						),
+					},
+					{
+						ResourceName:            "travis_key_pair.foo",
+						ImportState:             true,
+						ImportStateVerify:       true,
+						ImportStateVerifyIgnore: []string{"value"},
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestResourceKeyPair_defaultRepository(t *testing.T) {
+	api := newFakeAPI(t)
+	config := func(providerArgs string) string {
+		return fmt.Sprintf(`
+provider "travis" {
+	api_base_url = "%s/"
+	token        = "fake"
+	%s
+}
+
+resource "travis_key_pair" "foo" {
+	description = "default"
+	value       = "private key"
+}
+`, api.URL, providerArgs)
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(""),
+				ExpectError: regexp.MustCompile("one of repository_id or repository_slug must be specified"),
+			},
+			{
+				Config: config(fmt.Sprintf("default_repository_slug = %q", fakeRepoSlug)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_slug", fakeRepoSlug),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "description", "default"),
+				),
+			},
+			{
+				Config:   config(fmt.Sprintf("default_repository_slug = %q", fakeRepoSlug)),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestResourceKeyPair_renameRepository(t *testing.T) {
+	api := newFakeAPI(t)
+	config := func(slug, desc string) string {
+		return api.providerConfig() + fmt.Sprintf(`
+resource "travis_key_pair" "foo" {
+	repository_slug = %q
+	description     = %q
+	value           = "private key"
+}
+`, slug, desc)
+	}
+	const renamedSlug = "fake-owner/renamed-repo"
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config(fakeRepoSlug, "before"),
+			},
+			{
+				// the refresh keeps the old slug in the state, so the unchanged configuration plans nothing
+				PreConfig: func() { api.rename(renamedSlug) },
+				Config:    config(fakeRepoSlug, "before"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_slug", fakeRepoSlug),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "description", "before"),
+				),
+			},
+			{
+				Config: config(renamedSlug, "after"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_id", strconv.Itoa(fakeRepoID)),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "repository_slug", renamedSlug),
+					resource.TestCheckResourceAttr("travis_key_pair.foo", "description", "after"),
+					func(*terraform.State) error {
+						if n := api.countRequests(http.MethodDelete, "/repo/"); n > 0 {
+							return fmt.Errorf("key pair was deleted %d times while renaming the repository", n)
+						}
+						return nil
+					},
+				),
+			},
 		},
 	})
 }
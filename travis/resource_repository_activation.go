@@ -0,0 +1,165 @@
+package travis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/shuheiktgw/go-travis"
+)
+
+func resourceRepositoryActivation() *schema.Resource {
+	return &schema.Resource{
+		Description: "The `travis_repository_activation` resource activates a repository on create and deactivates it on destroy.",
+
+		CreateContext: resourceRepositoryActivationCreate,
+		ReadContext:   resourceRepositoryActivationRead,
+		UpdateContext: resourceRepositoryActivationUpdate,
+		DeleteContext: resourceRepositoryActivationDelete,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Value uniquely identifying the repository.",
+				ExactlyOneOf: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Same as {repository.owner.name}/{repository.name}. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ExactlyOneOf: []string{"repository_id"},
+			},
+			"active": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is currently enabled on Travis CI.",
+			},
+			"private": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether or not this repository is private.",
+			},
+			"default_branch": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The default branch on GitHub.",
+			},
+		},
+
+		CustomizeDiff: customizeConfiguredRepository,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importRepositoryActivation,
+		},
+	}
+}
+
+func resourceRepositoryActivationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	repo, err := repositoryIdentifier(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	repository, _, err := client.Repositories.Activate(ctx, repo)
+	if err != nil {
+		return diag.Errorf("error activating repo (%s): %s", repo, err)
+	}
+	client.rememberRepository(repository)
+	if err := assignRepositoryActivation(repository, d); err != nil {
+		return diag.Errorf("failed to assign repository: %v", err)
+	}
+	return assignRepositoryIdentifiers(ctx, client, d)
+}
+
+func resourceRepositoryActivationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	repo, err := repositoryIdentifier(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	repository, _, err := client.Repositories.Find(ctx, repo, nil)
+	if err != nil {
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("error reading repo (%s): %s", repo, err)
+	}
+	if repository.Active == nil || !*repository.Active {
+		// deactivated outside of Terraform
+		d.SetId("")
+		return nil
+	}
+	client.rememberRepository(repository)
+	if err := assignRepositoryActivation(repository, d); err != nil {
+		return diag.Errorf("failed to assign repository: %v", err)
+	}
+	return assignRepositoryIdentifiers(ctx, client, d)
+}
+
+// resourceRepositoryActivationUpdate only follows a rename of the repository.
+func resourceRepositoryActivationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return append(repositoryRenamed(d), resourceRepositoryActivationRead(ctx, d, m)...)
+}
+
+func resourceRepositoryActivationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*Client)
+	repo, err := repositoryIdentifier(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if _, _, err := client.Repositories.Deactivate(ctx, repo); err != nil {
+		if !isNotFound(err) {
+			return diag.Errorf("error deactivating repo (%s): %s", repo, err)
+		}
+	}
+	d.SetId("")
+	return nil
+}
+
+// repositoryIdentifier returns the repository ID or slug, both of which the repository endpoints accept.
+func repositoryIdentifier(d *schema.ResourceData) (string, error) {
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		return strconv.Itoa(repoID), nil
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		return repoSlug, nil
+	}
+	return "", fmt.Errorf("one of repository_id or repository_slug must be specified")
+}
+
+func assignRepositoryActivation(repository *travis.Repository, d *schema.ResourceData) error {
+	if repository.Id != nil {
+		d.SetId(strconv.FormatUint(uint64(*repository.Id), 10))
+	}
+	if err := d.Set("active", repository.Active); err != nil {
+		return err
+	}
+	if err := d.Set("private", repository.Private); err != nil {
+		return err
+	}
+	if repository.DefaultBranch != nil {
+		if err := d.Set("default_branch", repository.DefaultBranch.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importRepositoryActivation(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	repo := d.Id()
+	if repoID, err := strconv.Atoi(repo); err == nil {
+		if err := d.Set("repository_id", repoID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := d.Set("repository_slug", repo); err != nil {
+			return nil, err
+		}
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,79 @@
+package travis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	tptravis "github.com/bgpat/terraform-provider-travis/travis"
+)
+
+func TestAccResourceRepositoryActivation_basic(t *testing.T) {
+	t.Cleanup(func() {
+		// the other acceptance tests require the repository to be active
+		client, ok := testAccProvider.Meta().(*tptravis.Client)
+		if !ok {
+			// the provider is not configured when the test is skipped
+			return
+		}
+		if _, _, err := client.Repositories.Activate(context.Background(), testRepoSlug); err != nil {
+			t.Errorf("failed to reactivate repo %q: %v", testRepoSlug, err)
+		}
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRepositoryActivationResourceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRepositoryActivationResource(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("travis_repository_activation.foo", "repository_slug", testRepoSlug),
+					resource.TestCheckResourceAttr("travis_repository_activation.foo", "active", "true"),
+					resource.TestCheckResourceAttrSet("travis_repository_activation.foo", "id"),
+					resource.TestCheckResourceAttrSet("travis_repository_activation.foo", "private"),
+					resource.TestCheckResourceAttrSet("travis_repository_activation.foo", "default_branch"),
+				),
+			},
+			{
+				ResourceName:      "travis_repository_activation.foo",
+				ImportState:       true,
+				ImportStateId:     testRepoSlug,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckRepositoryActivationResourceDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*tptravis.Client)
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "travis_repository_activation" {
+			continue
+		}
+		repo, _, err := client.Repositories.Find(context.Background(), rs.Primary.ID, nil)
+		if err != nil {
+			if tptravis.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if repo.Active != nil && *repo.Active {
+			return fmt.Errorf("repo %q is still active", rs.Primary.ID)
+		}
+		return nil
+	}
+	return nil
+}
+
+func testAccRepositoryActivationResource() string {
+	return fmt.Sprintf(`
+resource "travis_repository_activation" "foo" {
+	repository_slug = %q
+}
+`, testRepoSlug)
+}
@@ -0,0 +1,248 @@
+package travis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/shuheiktgw/go-travis"
+)
+
+// repositorySettingNames are the settings managed by the travis_repository_settings resource.
+var repositorySettingNames = []string{
+	travis.BuildsOnlyWithTravisYmlSetting,
+	travis.BuildPushesSetting,
+	travis.BuildPullRequestsSetting,
+	travis.MaximumNumberOfBuildsSetting,
+	travis.AutoCancelPushesSetting,
+	travis.AutoCancelPullRequestsSetting,
+	"allow_config_imports",
+	"config_validation",
+	"share_encrypted_env_with_forks",
+	"share_ssh_keys_with_forks",
+}
+
+func resourceRepositorySettings() *schema.Resource {
+	return &schema.Resource{
+		Description: "The `travis_repository_settings` resource manages the build settings of a repository. " +
+			"Settings which are not specified are left as they are.",
+
+		CreateContext: resourceRepositorySettingsCreate,
+		ReadContext:   resourceRepositorySettingsRead,
+		UpdateContext: resourceRepositorySettingsUpdate,
+		DeleteContext: resourceRepositorySettingsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"repository_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Value uniquely identifying the repository.",
+				ExactlyOneOf: []string{"repository_slug"},
+			},
+			"repository_slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Same as {repository.owner.name}/{repository.name}. Changing it to the new slug of a renamed or transferred repository updates the resource in place.",
+				ExactlyOneOf: []string{"repository_id"},
+			},
+			"builds_only_with_travis_yml": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to build only if a .travis.yml is present.",
+			},
+			"build_pushes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to build pushed branches.",
+			},
+			"build_pull_requests": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to build pull requests.",
+			},
+			"maximum_number_of_builds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The maximum number of concurrent jobs. 0 means no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"auto_cancel_pushes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to cancel queued builds of pushed branches when a newer build is queued.",
+			},
+			"auto_cancel_pull_requests": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to cancel queued builds of pull requests when a newer build is queued.",
+			},
+			"allow_config_imports": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to allow other repositories to import build configs from this repository.",
+			},
+			"config_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to validate the build config.",
+			},
+			"share_encrypted_env_with_forks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to share encrypted environment variables with pull requests from forks.",
+			},
+			"share_ssh_keys_with_forks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether to share SSH keys with pull requests from forks.",
+			},
+		},
+
+		CustomizeDiff: customizeConfiguredRepository,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importRepositorySettings,
+		},
+	}
+}
+
+func resourceRepositorySettingsCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := d.GetRawConfig()
+	for _, name := range repositorySettingNames {
+		if config.GetAttr(name).IsNull() {
+			continue
+		}
+		if err := updateRepositorySetting(ctx, d, m, name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return resourceRepositorySettingsRead(ctx, d, m)
+}
+
+func resourceRepositorySettingsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var (
+		client   = m.(*Client)
+		settings []*travis.Setting
+		err      error
+	)
+	diags := assignRepositoryIdentifiers(ctx, client, d)
+	if diags.HasError() {
+		return diags
+	}
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		settings, _, err = client.Settings.ListByRepoId(ctx, uint(repoID))
+		if err != nil {
+			if isNotFound(err) {
+				d.SetId("")
+				return nil
+			}
+			return diag.Errorf("error reading settings by repo ID (%d): %s", repoID, err)
+		}
+		d.SetId(strconv.Itoa(repoID))
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		settings, _, err = client.Settings.ListByRepoSlug(ctx, repoSlug)
+		if err != nil {
+			if isNotFound(err) {
+				d.SetId("")
+				return nil
+			}
+			return diag.Errorf("error reading settings by repo slug (%s): %s", repoSlug, err)
+		}
+		d.SetId(repoSlug)
+	} else {
+		return diag.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	if err := assignRepositorySettings(settings, d); err != nil {
+		return diag.Errorf("failed to assign settings: %v", err)
+	}
+	return diags
+}
+
+func resourceRepositorySettingsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	for _, name := range repositorySettingNames {
+		if !d.HasChange(name) {
+			continue
+		}
+		if err := updateRepositorySetting(ctx, d, m, name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return append(repositoryRenamed(d), resourceRepositorySettingsRead(ctx, d, m)...)
+}
+
+func resourceRepositorySettingsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Settings cannot be deleted, so they are only removed from the state.
+	d.SetId("")
+	return nil
+}
+
+func updateRepositorySetting(ctx context.Context, d *schema.ResourceData, m interface{}, name string) error {
+	client := m.(*Client)
+	body := &travis.SettingBody{
+		Name:  name,
+		Value: d.Get(name),
+	}
+	if repoID := d.Get("repository_id").(int); repoID > 0 {
+		if _, _, err := client.Settings.UpdateByRepoId(ctx, uint(repoID), body); err != nil {
+			return fmt.Errorf("error updating setting %q by repo ID (%d): %w", name, repoID, err)
+		}
+	} else if repoSlug := d.Get("repository_slug").(string); repoSlug != "" {
+		if _, _, err := client.Settings.UpdateByRepoSlug(ctx, repoSlug, body); err != nil {
+			return fmt.Errorf("error updating setting %q by repo slug (%s): %w", name, repoSlug, err)
+		}
+	} else {
+		return fmt.Errorf("one of repository_id or repository_slug must be specified")
+	}
+	return nil
+}
+
+func assignRepositorySettings(settings []*travis.Setting, d *schema.ResourceData) error {
+	values := make(map[string]interface{}, len(settings))
+	for _, setting := range settings {
+		if setting.Name != nil {
+			values[*setting.Name] = setting.Value
+		}
+	}
+	for _, name := range repositorySettingNames {
+		switch value := values[name].(type) {
+		case bool:
+			if err := d.Set(name, value); err != nil {
+				return err
+			}
+		case float64:
+			if err := d.Set(name, int(value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func importRepositorySettings(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	repo := d.Id()
+	if repoID, err := strconv.Atoi(repo); err == nil {
+		if err := d.Set("repository_id", repoID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := d.Set("repository_slug", repo); err != nil {
+			return nil, err
+		}
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,72 @@
+package travis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/shuheiktgw/go-travis"
+
+	tptravis "github.com/bgpat/terraform-provider-travis/travis"
+)
+
+func TestAccResourceRepositorySettings_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRepositorySettingsResource(true, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRepositorySetting(travis.AutoCancelPushesSetting, true),
+					testAccCheckRepositorySetting(travis.MaximumNumberOfBuildsSetting, float64(2)),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "id", testRepoSlug),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "repository_slug", testRepoSlug),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "auto_cancel_pushes", "true"),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "maximum_number_of_builds", "2"),
+					resource.TestCheckResourceAttrSet("travis_repository_settings.foo", "build_pushes"),
+				),
+			},
+			{
+				Config: testAccRepositorySettingsResource(false, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRepositorySetting(travis.AutoCancelPushesSetting, false),
+					testAccCheckRepositorySetting(travis.MaximumNumberOfBuildsSetting, float64(0)),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "auto_cancel_pushes", "false"),
+					resource.TestCheckResourceAttr("travis_repository_settings.foo", "maximum_number_of_builds", "0"),
+				),
+			},
+			{
+				ResourceName:      "travis_repository_settings.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckRepositorySetting(name string, want interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*tptravis.Client)
+		setting, _, err := client.Settings.FindByRepoSlug(context.Background(), testRepoSlug, name)
+		if err != nil {
+			return err
+		}
+		if setting.Value != want {
+			return fmt.Errorf("setting %q is %v, want %v", name, setting.Value, want)
+		}
+		return nil
+	}
+}
+
+func testAccRepositorySettingsResource(autoCancelPushes bool, maximumNumberOfBuilds int) string {
+	return fmt.Sprintf(`
+resource "travis_repository_settings" "foo" {
+	repository_slug          = %q
+	auto_cancel_pushes       = %t
+	maximum_number_of_builds = %d
+}
+`, testRepoSlug, autoCancelPushes, maximumNumberOfBuilds)
+}
@@ -0,0 +1,98 @@
+package travis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenReloadInterval prevents reloading the token on every rejected request, e.g. by missing permissions.
+const tokenReloadInterval = 10 * time.Second
+
+// TokenLoader loads the API access token.
+type TokenLoader func(ctx context.Context) (string, error)
+
+// tokenSource caches the token loaded by TokenLoader until it is refreshed.
+type tokenSource struct {
+	mu             sync.Mutex
+	load           TokenLoader
+	reloadInterval time.Duration
+	current        string
+	loadedAt       time.Time
+}
+
+// token returns the cached token, loading it for the first time.
+func (s *tokenSource) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != "" {
+		return s.current, nil
+	}
+	return s.reload(ctx)
+}
+
+// refresh reloads the token rejected by the API.
+// If another request has already refreshed it or it has been loaded recently, the current token is returned without loading.
+func (s *tokenSource) refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != rejected || time.Since(s.loadedAt) < s.reloadInterval {
+		return s.current, nil
+	}
+	return s.reload(ctx)
+}
+
+// reload must be called with s.mu held.
+func (s *tokenSource) reload(ctx context.Context) (string, error) {
+	token, err := s.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.current = token
+	s.loadedAt = time.Now()
+	return token, nil
+}
+
+// TokenFromFile returns TokenLoader reading the token from the file.
+func TokenFromFile(path string) TokenLoader {
+	return func(ctx context.Context) (string, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		token := strings.TrimSpace(string(b))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", path)
+		}
+		return token, nil
+	}
+}
+
+// TokenFromCommand returns TokenLoader reading the token from the stdout of the command.
+func TokenFromCommand(args []string) TokenLoader {
+	return func(ctx context.Context) (string, error) {
+		if len(args) == 0 {
+			return "", errors.New("token command is empty")
+		}
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("failed to run token command: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		token := strings.TrimSpace(stdout.String())
+		if token == "" {
+			return "", errors.New("token command printed nothing")
+		}
+		return token, nil
+	}
+}
@@ -0,0 +1,119 @@
+package travis
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTokenFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("  secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := TokenFromFile(path)(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "secret" {
+		t.Errorf("unexpected token: %q", token)
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TokenFromFile(path)(context.Background()); err == nil {
+		t.Error("expected an error for the empty file")
+	}
+}
+
+func TestTokenFromCommand(t *testing.T) {
+	token, err := TokenFromCommand([]string{"echo", "secret"})(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "secret" {
+		t.Errorf("unexpected token: %q", token)
+	}
+
+	if _, err := TokenFromCommand([]string{"false"})(context.Background()); err == nil {
+		t.Error("expected an error for the failed command")
+	}
+}
+
+func TestRoundTripper_reloadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("rotated"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+			t.Errorf("unexpected body: %q", body)
+		}
+		if r.Header.Get("Authorization") != "token rotated" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := newRoundTripper(http.DefaultTransport, WithTokenLoader("expired", TokenFromFile(path)))
+	transport.tokens.reloadInterval = 0
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL+"/repo/1234/env_vars", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+
+	// the reloaded token is used for the following requests
+	resp, err = client.Post(server.URL+"/repo/1234/env_vars", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+}
+
+func TestTokenSource_reloadInterval(t *testing.T) {
+	var loads int32
+	s := &tokenSource{
+		load: func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&loads, 1)
+			return "same", nil
+		},
+		reloadInterval: tokenReloadInterval,
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		token, err := s.token(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.refresh(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("the token must not be reloaded within the interval, but loaded %d times", n)
+	}
+}
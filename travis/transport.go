@@ -0,0 +1,88 @@
+package travis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportConfig is the TLS and proxy configuration of the HTTP transport.
+type TransportConfig struct {
+	// CACertFile is the path to PEM-encoded CA certificates trusted in addition to the system ones.
+	CACertFile string
+	// CACertPEM is PEM-encoded CA certificates trusted in addition to the system ones.
+	CACertPEM string
+	// ClientCert and ClientKey are the PEM-encoded certificate and key for TLS client authentication.
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+	// ProxyURL is the URL of the proxy. If empty, the proxy is taken from the environment variables.
+	ProxyURL string
+}
+
+// WithTransportConfig replaces the base HTTP transport with the one configured by cfg.
+func WithTransportConfig(cfg *TransportConfig) (ClientOption, error) {
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, err
+	}
+	return func(r *roundTripper) {
+		r.base = transport
+	}, nil
+}
+
+func (cfg *TransportConfig) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACertFile != "" || cfg.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CACertFile != "" {
+			pem, err := os.ReadFile(cfg.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no CA certificates found in %s", cfg.CACertFile)
+			}
+		}
+		if cfg.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(cfg.CACertPEM)) {
+			return nil, errors.New("no CA certificates found in ca_cert_pem")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport, nil
+}
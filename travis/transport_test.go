@@ -0,0 +1,88 @@
+package travis
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransportConfig_caCert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(caPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		cfg     TransportConfig
+		wantErr bool
+	}{
+		"system":       {cfg: TransportConfig{}, wantErr: true},
+		"ca_cert_pem":  {cfg: TransportConfig{CACertPEM: caPEM}},
+		"ca_cert_file": {cfg: TransportConfig{CACertFile: caFile}},
+		"insecure":     {cfg: TransportConfig{InsecureSkipVerify: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			transport, err := tc.cfg.transport()
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if tc.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("expected a certificate error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestTransportConfig_proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(proxy.Close)
+
+	transport, err := (&TransportConfig{ProxyURL: proxy.URL}).transport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get("http://travis.example.com/repo/1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if proxied != "http://travis.example.com/repo/1234" {
+		t.Errorf("request is not sent via the proxy: %q", proxied)
+	}
+}
+
+func TestTransportConfig_invalid(t *testing.T) {
+	for name, cfg := range map[string]TransportConfig{
+		"ca_cert_pem":  {CACertPEM: "not a certificate"},
+		"ca_cert_file": {CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"client_cert":  {ClientCert: "not a certificate", ClientKey: "not a key"},
+		"proxy_url":    {ProxyURL: "ftp://proxy.example.com"},
+	} {
+		if _, err := cfg.transport(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}